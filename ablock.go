@@ -0,0 +1,735 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+const AdminChainID = "000000000000000000000000000000000000000000000000000000000000000a"
+
+// Admin Block entry types
+const (
+	AdminIDMinuteNumber = iota
+	AdminIDDBSignature
+	AdminIDRevealMatryoshkaHash
+	AdminIDAddReplaceMatryoshkaHash
+	AdminIDIncreaseServerCount
+	AdminIDAddFederatedServer
+	AdminIDAddAuditServer
+	AdminIDRemoveFederatedServer
+	AdminIDAddFederatedServerSigningKey
+	AdminIDAddFederatedServerBitcoinAnchorKey
+	AdminIDServerFaultHandoff
+	AdminIDCoinbaseDescriptor
+	AdminIDCoinbaseDescriptorCancel
+	AdminIDAddAuthorityFactoidAddress
+	AdminIDAddAuthorityEfficiency
+)
+
+// ABlock is an Admin Block recording the signatures on the previous Directory
+// Block and the changes to the federated server set.
+type ABlock struct {
+	KeyMR           string
+	PrevBackRefHash string
+	DBHeight        uint32
+	Entries         []ABlockEntry
+}
+
+// ABlockEntry is one of the Admin Block entry types below. Entries with types
+// this library does not know are kept as *UnknownAdminEntry.
+type ABlockEntry interface {
+	AdminID() byte
+	MarshalBinary() ([]byte, error)
+	unmarshal(buf *bytes.Buffer) error
+}
+
+// AdminMinuteNumber marks the end of a minute in older Admin Blocks.
+type AdminMinuteNumber struct {
+	Number uint8
+}
+
+// DBSignature is a federated server signature of the previous Directory
+// Block.
+type DBSignature struct {
+	IdentityChainID string
+	PubKey          []byte
+	Sig             []byte
+}
+
+// RevealMatryoshkaHash reveals a server's Matryoshka hash.
+type RevealMatryoshkaHash struct {
+	IdentityChainID string
+	MHash           string
+}
+
+// AddReplaceMatryoshkaHash sets a server's Matryoshka hash.
+type AddReplaceMatryoshkaHash struct {
+	IdentityChainID string
+	MHash           string
+}
+
+// IncreaseServerCount increases the number of federated servers.
+type IncreaseServerCount struct {
+	Amount uint8
+}
+
+// AddFederatedServer adds the identity to the federated server set at the
+// Directory Block height.
+type AddFederatedServer struct {
+	IdentityChainID string
+	DBHeight        uint32
+}
+
+// AddAuditServer adds the identity to the audit server set at the Directory
+// Block height.
+type AddAuditServer struct {
+	IdentityChainID string
+	DBHeight        uint32
+}
+
+// RemoveFederatedServer removes the identity from the federated or audit
+// server set at the Directory Block height.
+type RemoveFederatedServer struct {
+	IdentityChainID string
+	DBHeight        uint32
+}
+
+// AddFederatedServerSigningKey sets the block signing key of a server.
+type AddFederatedServerSigningKey struct {
+	IdentityChainID string
+	KeyPriority     uint8
+	PubKey          []byte
+	DBHeight        uint32
+}
+
+// AddFederatedServerBitcoinAnchorKey sets the key a server uses to anchor into
+// Bitcoin.
+type AddFederatedServerBitcoinAnchorKey struct {
+	IdentityChainID string
+	KeyPriority     uint8
+	KeyType         uint8
+	ECDSAPubKey     []byte
+}
+
+// ServerFaultHandoff replaces the faulted server ServerID with the audit
+// server AuditServerID for the VM at the Directory Block and minute height,
+// with the signatures of the servers that agreed.
+type ServerFaultHandoff struct {
+	MilliTimestamp int64
+	ServerID       string
+	AuditServerID  string
+	VMIndex        uint8
+	DBHeight       uint32
+	Height         uint32
+	Signatures     []*ServerFaultSignature
+}
+
+// ServerFaultSignature is a server's signature of a ServerFaultHandoff.
+type ServerFaultSignature struct {
+	PubKey []byte
+	Sig    []byte
+}
+
+// CoinbaseDescriptor lists the outputs of the coinbase Factoid Transaction
+// paid in a later Factoid Block.
+type CoinbaseDescriptor struct {
+	Outputs []*TransAddress
+}
+
+// CoinbaseDescriptorCancel cancels an output of an earlier CoinbaseDescriptor.
+type CoinbaseDescriptorCancel struct {
+	DescriptorHeight uint32
+	DescriptorIndex  uint32
+}
+
+// AddAuthorityFactoidAddress sets the Factoid address an authority is paid
+// to.
+type AddAuthorityFactoidAddress struct {
+	IdentityChainID string
+	FactoidAddress  []byte
+}
+
+// AddAuthorityEfficiency sets the share of an authority's payment that goes
+// to the grant pool, in hundredths of a percent.
+type AddAuthorityEfficiency struct {
+	IdentityChainID string
+	Efficiency      uint16
+}
+
+// UnknownAdminEntry is a length prefixed Admin Block entry of a type this
+// library does not decode.
+type UnknownAdminEntry struct {
+	ID   byte
+	Data []byte
+}
+
+// GetABlock fetches and decodes the Admin Block with the KeyMR.
+func GetABlock(keymr string) (*ABlock, error) {
+	raw, err := GetRaw(keymr)
+	if err != nil {
+		return nil, err
+	}
+
+	a := new(ABlock)
+	if err := a.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	a.KeyMR = keymr
+
+	return a, nil
+}
+
+func (a *ABlock) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	// 32 byte ChainID
+	p, _ := hex.DecodeString(AdminChainID)
+	buf.Write(p)
+
+	// 32 byte Previous Back Reference Hash
+	if err := writeHash(buf, a.PrevBackRefHash); err != nil {
+		return nil, err
+	}
+
+	// 4 byte Directory Block Height
+	binary.Write(buf, binary.BigEndian, a.DBHeight)
+
+	// varint Header Expansion Size
+	writeVarInt(buf, 0)
+
+	body := new(bytes.Buffer)
+	for _, v := range a.Entries {
+		p, err := v.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		body.WriteByte(v.AdminID())
+		body.Write(p)
+	}
+
+	// 4 byte Message Count
+	binary.Write(buf, binary.BigEndian, uint32(len(a.Entries)))
+
+	// 4 byte Body Size
+	binary.Write(buf, binary.BigEndian, uint32(body.Len()))
+
+	buf.Write(body.Bytes())
+
+	return buf.Bytes(), nil
+}
+
+func (a *ABlock) UnmarshalBinary(data []byte) error {
+	buf := bytes.NewBuffer(data)
+
+	if buf.Len() < 32*2+4 {
+		return fmt.Errorf("Admin Block header is too short")
+	}
+
+	// 32 byte ChainID
+	if c := hex.EncodeToString(buf.Next(32)); c != AdminChainID {
+		return fmt.Errorf("Invalid Admin Block ChainID %s", c)
+	}
+
+	a.PrevBackRefHash = hex.EncodeToString(buf.Next(32))
+	a.DBHeight = binary.BigEndian.Uint32(buf.Next(4))
+
+	if err := skipExpansion(buf); err != nil {
+		return err
+	}
+
+	if buf.Len() < 8 {
+		return fmt.Errorf("Admin Block header is too short")
+	}
+	count := binary.BigEndian.Uint32(buf.Next(4))
+	size := binary.BigEndian.Uint32(buf.Next(4))
+	if uint32(buf.Len()) != size {
+		return fmt.Errorf("Admin Block body is %d bytes, expected %d",
+			buf.Len(), size)
+	}
+
+	a.Entries = nil
+	for i := uint32(0); i < count; i++ {
+		id, err := buf.ReadByte()
+		if err != nil {
+			return fmt.Errorf("Admin Block body ended after %d entries", i)
+		}
+
+		v := newABlockEntry(id)
+		if err := v.unmarshal(buf); err != nil {
+			return fmt.Errorf("Admin Block entry %d: %s", i, err)
+		}
+		a.Entries = append(a.Entries, v)
+	}
+
+	return nil
+}
+
+// newABlockEntry returns an empty entry of the Admin ID. Unknown IDs, which are
+// all in the length prefixed range, are returned as *UnknownAdminEntry.
+func newABlockEntry(id byte) ABlockEntry {
+	switch id {
+	case AdminIDMinuteNumber:
+		return new(AdminMinuteNumber)
+	case AdminIDDBSignature:
+		return new(DBSignature)
+	case AdminIDRevealMatryoshkaHash:
+		return new(RevealMatryoshkaHash)
+	case AdminIDAddReplaceMatryoshkaHash:
+		return new(AddReplaceMatryoshkaHash)
+	case AdminIDIncreaseServerCount:
+		return new(IncreaseServerCount)
+	case AdminIDAddFederatedServer:
+		return new(AddFederatedServer)
+	case AdminIDAddAuditServer:
+		return new(AddAuditServer)
+	case AdminIDRemoveFederatedServer:
+		return new(RemoveFederatedServer)
+	case AdminIDAddFederatedServerSigningKey:
+		return new(AddFederatedServerSigningKey)
+	case AdminIDAddFederatedServerBitcoinAnchorKey:
+		return new(AddFederatedServerBitcoinAnchorKey)
+	case AdminIDServerFaultHandoff:
+		return new(ServerFaultHandoff)
+	case AdminIDCoinbaseDescriptor:
+		return new(CoinbaseDescriptor)
+	case AdminIDCoinbaseDescriptorCancel:
+		return new(CoinbaseDescriptorCancel)
+	case AdminIDAddAuthorityFactoidAddress:
+		return new(AddAuthorityFactoidAddress)
+	case AdminIDAddAuthorityEfficiency:
+		return new(AddAuthorityEfficiency)
+	}
+	return &UnknownAdminEntry{ID: id}
+}
+
+func (a *ABlock) String() string {
+	var s string
+	s += fmt.Sprintln("KeyMR:", a.KeyMR)
+	s += fmt.Sprintln("PrevBackRefHash:", a.PrevBackRefHash)
+	s += fmt.Sprintln("DBHeight:", a.DBHeight)
+	for _, v := range a.Entries {
+		s += fmt.Sprintf("%T %+v\n", v, v)
+	}
+	return s
+}
+
+func (m *AdminMinuteNumber) AdminID() byte {
+	return AdminIDMinuteNumber
+}
+
+func (m *AdminMinuteNumber) MarshalBinary() ([]byte, error) {
+	return []byte{m.Number}, nil
+}
+
+func (m *AdminMinuteNumber) unmarshal(buf *bytes.Buffer) (err error) {
+	m.Number, err = buf.ReadByte()
+	return
+}
+
+func (d *DBSignature) AdminID() byte {
+	return AdminIDDBSignature
+}
+
+func (d *DBSignature) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := writeHash(buf, d.IdentityChainID); err != nil {
+		return nil, err
+	}
+	if len(d.PubKey) != 32 || len(d.Sig) != 64 {
+		return nil, fmt.Errorf("Invalid DBSignature")
+	}
+	buf.Write(d.PubKey)
+	buf.Write(d.Sig)
+	return buf.Bytes(), nil
+}
+
+func (d *DBSignature) unmarshal(buf *bytes.Buffer) error {
+	if buf.Len() < 128 {
+		return fmt.Errorf("DBSignature is too short")
+	}
+	d.IdentityChainID = hex.EncodeToString(buf.Next(32))
+	d.PubKey = append([]byte{}, buf.Next(32)...)
+	d.Sig = append([]byte{}, buf.Next(64)...)
+	return nil
+}
+
+func (r *RevealMatryoshkaHash) AdminID() byte {
+	return AdminIDRevealMatryoshkaHash
+}
+
+func (r *RevealMatryoshkaHash) MarshalBinary() ([]byte, error) {
+	return marshalHashes(r.IdentityChainID, r.MHash)
+}
+
+func (r *RevealMatryoshkaHash) unmarshal(buf *bytes.Buffer) error {
+	return unmarshalHashes(buf, &r.IdentityChainID, &r.MHash)
+}
+
+func (r *AddReplaceMatryoshkaHash) AdminID() byte {
+	return AdminIDAddReplaceMatryoshkaHash
+}
+
+func (r *AddReplaceMatryoshkaHash) MarshalBinary() ([]byte, error) {
+	return marshalHashes(r.IdentityChainID, r.MHash)
+}
+
+func (r *AddReplaceMatryoshkaHash) unmarshal(buf *bytes.Buffer) error {
+	return unmarshalHashes(buf, &r.IdentityChainID, &r.MHash)
+}
+
+func (i *IncreaseServerCount) AdminID() byte {
+	return AdminIDIncreaseServerCount
+}
+
+func (i *IncreaseServerCount) MarshalBinary() ([]byte, error) {
+	return []byte{i.Amount}, nil
+}
+
+func (i *IncreaseServerCount) unmarshal(buf *bytes.Buffer) (err error) {
+	i.Amount, err = buf.ReadByte()
+	return
+}
+
+func (a *AddFederatedServer) AdminID() byte {
+	return AdminIDAddFederatedServer
+}
+
+func (a *AddFederatedServer) MarshalBinary() ([]byte, error) {
+	return marshalServer(a.IdentityChainID, a.DBHeight)
+}
+
+func (a *AddFederatedServer) unmarshal(buf *bytes.Buffer) error {
+	return unmarshalServer(buf, &a.IdentityChainID, &a.DBHeight)
+}
+
+func (a *AddAuditServer) AdminID() byte {
+	return AdminIDAddAuditServer
+}
+
+func (a *AddAuditServer) MarshalBinary() ([]byte, error) {
+	return marshalServer(a.IdentityChainID, a.DBHeight)
+}
+
+func (a *AddAuditServer) unmarshal(buf *bytes.Buffer) error {
+	return unmarshalServer(buf, &a.IdentityChainID, &a.DBHeight)
+}
+
+func (r *RemoveFederatedServer) AdminID() byte {
+	return AdminIDRemoveFederatedServer
+}
+
+func (r *RemoveFederatedServer) MarshalBinary() ([]byte, error) {
+	return marshalServer(r.IdentityChainID, r.DBHeight)
+}
+
+func (r *RemoveFederatedServer) unmarshal(buf *bytes.Buffer) error {
+	return unmarshalServer(buf, &r.IdentityChainID, &r.DBHeight)
+}
+
+func (a *AddFederatedServerSigningKey) AdminID() byte {
+	return AdminIDAddFederatedServerSigningKey
+}
+
+func (a *AddFederatedServerSigningKey) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := writeHash(buf, a.IdentityChainID); err != nil {
+		return nil, err
+	}
+	if len(a.PubKey) != 32 {
+		return nil, fmt.Errorf("Invalid signing key length %d", len(a.PubKey))
+	}
+	buf.WriteByte(a.KeyPriority)
+	buf.Write(a.PubKey)
+	binary.Write(buf, binary.BigEndian, a.DBHeight)
+	return buf.Bytes(), nil
+}
+
+func (a *AddFederatedServerSigningKey) unmarshal(buf *bytes.Buffer) error {
+	if buf.Len() < 32+1+32+4 {
+		return fmt.Errorf("AddFederatedServerSigningKey is too short")
+	}
+	a.IdentityChainID = hex.EncodeToString(buf.Next(32))
+	a.KeyPriority, _ = buf.ReadByte()
+	a.PubKey = append([]byte{}, buf.Next(32)...)
+	a.DBHeight = binary.BigEndian.Uint32(buf.Next(4))
+	return nil
+}
+
+func (a *AddFederatedServerBitcoinAnchorKey) AdminID() byte {
+	return AdminIDAddFederatedServerBitcoinAnchorKey
+}
+
+func (a *AddFederatedServerBitcoinAnchorKey) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := writeHash(buf, a.IdentityChainID); err != nil {
+		return nil, err
+	}
+	if len(a.ECDSAPubKey) != 20 {
+		return nil, fmt.Errorf("Invalid anchor key length %d", len(a.ECDSAPubKey))
+	}
+	buf.WriteByte(a.KeyPriority)
+	buf.WriteByte(a.KeyType)
+	buf.Write(a.ECDSAPubKey)
+	return buf.Bytes(), nil
+}
+
+func (a *AddFederatedServerBitcoinAnchorKey) unmarshal(buf *bytes.Buffer) error {
+	if buf.Len() < 32+1+1+20 {
+		return fmt.Errorf("AddFederatedServerBitcoinAnchorKey is too short")
+	}
+	a.IdentityChainID = hex.EncodeToString(buf.Next(32))
+	a.KeyPriority, _ = buf.ReadByte()
+	a.KeyType, _ = buf.ReadByte()
+	a.ECDSAPubKey = append([]byte{}, buf.Next(20)...)
+	return nil
+}
+
+func (f *ServerFaultHandoff) AdminID() byte {
+	return AdminIDServerFaultHandoff
+}
+
+func (f *ServerFaultHandoff) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.Write(milliBytes(f.MilliTimestamp))
+	if err := writeHash(buf, f.ServerID); err != nil {
+		return nil, err
+	}
+	if err := writeHash(buf, f.AuditServerID); err != nil {
+		return nil, err
+	}
+	buf.WriteByte(f.VMIndex)
+	binary.Write(buf, binary.BigEndian, f.DBHeight)
+	binary.Write(buf, binary.BigEndian, f.Height)
+	binary.Write(buf, binary.BigEndian, uint32(len(f.Signatures)))
+	for _, s := range f.Signatures {
+		if len(s.PubKey) != 32 || len(s.Sig) != 64 {
+			return nil, fmt.Errorf("Invalid ServerFaultSignature")
+		}
+		buf.Write(s.PubKey)
+		buf.Write(s.Sig)
+	}
+	return buf.Bytes(), nil
+}
+
+func (f *ServerFaultHandoff) unmarshal(buf *bytes.Buffer) error {
+	if buf.Len() < 6+32+32+1+4+4+4 {
+		return fmt.Errorf("ServerFaultHandoff is too short")
+	}
+	f.MilliTimestamp = readMilli(buf)
+	f.ServerID = hex.EncodeToString(buf.Next(32))
+	f.AuditServerID = hex.EncodeToString(buf.Next(32))
+	f.VMIndex, _ = buf.ReadByte()
+	f.DBHeight = binary.BigEndian.Uint32(buf.Next(4))
+	f.Height = binary.BigEndian.Uint32(buf.Next(4))
+
+	n := binary.BigEndian.Uint32(buf.Next(4))
+	if uint64(buf.Len()) < uint64(n)*(32+64) {
+		return fmt.Errorf("ServerFaultHandoff signatures are too short")
+	}
+	f.Signatures = make([]*ServerFaultSignature, n)
+	for i := range f.Signatures {
+		f.Signatures[i] = &ServerFaultSignature{
+			append([]byte{}, buf.Next(32)...),
+			append([]byte{}, buf.Next(64)...),
+		}
+	}
+	return nil
+}
+
+func (c *CoinbaseDescriptor) AdminID() byte {
+	return AdminIDCoinbaseDescriptor
+}
+
+func (c *CoinbaseDescriptor) MarshalBinary() ([]byte, error) {
+	body := new(bytes.Buffer)
+	for _, o := range c.Outputs {
+		if len(o.Address) != 32 || o.Amount < 0 {
+			return nil, fmt.Errorf("Invalid coinbase output")
+		}
+		writeVarInt(body, uint64(o.Amount))
+		body.Write(o.Address)
+	}
+	return sized(body.Bytes()), nil
+}
+
+func (c *CoinbaseDescriptor) unmarshal(buf *bytes.Buffer) error {
+	body, err := readSized(buf)
+	if err != nil {
+		return err
+	}
+	c.Outputs = nil
+	for body.Len() > 0 {
+		amt, err := readVarInt(body)
+		if err != nil {
+			return err
+		}
+		if body.Len() < 32 {
+			return fmt.Errorf("Coinbase output is too short")
+		}
+		c.Outputs = append(c.Outputs,
+			&TransAddress{Factoshi(amt), append([]byte{}, body.Next(32)...)})
+	}
+	return nil
+}
+
+func (c *CoinbaseDescriptorCancel) AdminID() byte {
+	return AdminIDCoinbaseDescriptorCancel
+}
+
+func (c *CoinbaseDescriptorCancel) MarshalBinary() ([]byte, error) {
+	body := new(bytes.Buffer)
+	binary.Write(body, binary.BigEndian, c.DescriptorHeight)
+	binary.Write(body, binary.BigEndian, c.DescriptorIndex)
+	return sized(body.Bytes()), nil
+}
+
+func (c *CoinbaseDescriptorCancel) unmarshal(buf *bytes.Buffer) error {
+	body, err := readSized(buf)
+	if err != nil {
+		return err
+	}
+	if body.Len() < 8 {
+		return fmt.Errorf("CoinbaseDescriptorCancel is too short")
+	}
+	c.DescriptorHeight = binary.BigEndian.Uint32(body.Next(4))
+	c.DescriptorIndex = binary.BigEndian.Uint32(body.Next(4))
+	return nil
+}
+
+func (a *AddAuthorityFactoidAddress) AdminID() byte {
+	return AdminIDAddAuthorityFactoidAddress
+}
+
+func (a *AddAuthorityFactoidAddress) MarshalBinary() ([]byte, error) {
+	body := new(bytes.Buffer)
+	if err := writeHash(body, a.IdentityChainID); err != nil {
+		return nil, err
+	}
+	if len(a.FactoidAddress) != 32 {
+		return nil, fmt.Errorf("Invalid address length %d", len(a.FactoidAddress))
+	}
+	body.Write(a.FactoidAddress)
+	return sized(body.Bytes()), nil
+}
+
+func (a *AddAuthorityFactoidAddress) unmarshal(buf *bytes.Buffer) error {
+	body, err := readSized(buf)
+	if err != nil {
+		return err
+	}
+	if body.Len() < 64 {
+		return fmt.Errorf("AddAuthorityFactoidAddress is too short")
+	}
+	a.IdentityChainID = hex.EncodeToString(body.Next(32))
+	a.FactoidAddress = append([]byte{}, body.Next(32)...)
+	return nil
+}
+
+func (a *AddAuthorityEfficiency) AdminID() byte {
+	return AdminIDAddAuthorityEfficiency
+}
+
+func (a *AddAuthorityEfficiency) MarshalBinary() ([]byte, error) {
+	body := new(bytes.Buffer)
+	if err := writeHash(body, a.IdentityChainID); err != nil {
+		return nil, err
+	}
+	binary.Write(body, binary.BigEndian, a.Efficiency)
+	return sized(body.Bytes()), nil
+}
+
+func (a *AddAuthorityEfficiency) unmarshal(buf *bytes.Buffer) error {
+	body, err := readSized(buf)
+	if err != nil {
+		return err
+	}
+	if body.Len() < 34 {
+		return fmt.Errorf("AddAuthorityEfficiency is too short")
+	}
+	a.IdentityChainID = hex.EncodeToString(body.Next(32))
+	a.Efficiency = binary.BigEndian.Uint16(body.Next(2))
+	return nil
+}
+
+func (u *UnknownAdminEntry) AdminID() byte {
+	return u.ID
+}
+
+func (u *UnknownAdminEntry) MarshalBinary() ([]byte, error) {
+	return sized(u.Data), nil
+}
+
+func (u *UnknownAdminEntry) unmarshal(buf *bytes.Buffer) error {
+	body, err := readSized(buf)
+	if err != nil {
+		return err
+	}
+	u.Data = body.Bytes()
+	return nil
+}
+
+// marshalHashes writes the hex hashes as 32 byte values
+func marshalHashes(hs ...string) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	for _, h := range hs {
+		if err := writeHash(buf, h); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func unmarshalHashes(buf *bytes.Buffer, hs ...*string) error {
+	if buf.Len() < 32*len(hs) {
+		return fmt.Errorf("Admin Block entry is too short")
+	}
+	for _, h := range hs {
+		*h = hex.EncodeToString(buf.Next(32))
+	}
+	return nil
+}
+
+// marshalServer writes a 32 byte Identity ChainID and a 4 byte height
+func marshalServer(id string, height uint32) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := writeHash(buf, id); err != nil {
+		return nil, err
+	}
+	binary.Write(buf, binary.BigEndian, height)
+	return buf.Bytes(), nil
+}
+
+func unmarshalServer(buf *bytes.Buffer, id *string, height *uint32) error {
+	if buf.Len() < 36 {
+		return fmt.Errorf("Admin Block entry is too short")
+	}
+	*id = hex.EncodeToString(buf.Next(32))
+	*height = binary.BigEndian.Uint32(buf.Next(4))
+	return nil
+}
+
+// sized prefixes p with its varint length
+func sized(p []byte) []byte {
+	buf := new(bytes.Buffer)
+	writeVarInt(buf, uint64(len(p)))
+	buf.Write(p)
+	return buf.Bytes()
+}
+
+// readSized reads a varint length prefixed body from buf
+func readSized(buf *bytes.Buffer) (*bytes.Buffer, error) {
+	n, err := readVarInt(buf)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(buf.Len()) < n {
+		return nil, fmt.Errorf("Admin Block entry is too short")
+	}
+	return bytes.NewBuffer(append([]byte{}, buf.Next(int(n))...)), nil
+}
@@ -0,0 +1,55 @@
+package factom_test
+
+import (
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+const identityChainID = "888888b6f2ee4a4f5b27d1fa9e2bbbf23e22e1e0c9be9d8a5e0b55f0a3f5e0a3"
+
+func TestABlockBinary(t *testing.T) {
+	a := new(factom.ABlock)
+	a.PrevBackRefHash = factom.ZeroHash
+	a.DBHeight = 12
+	a.Entries = []factom.ABlockEntry{
+		&factom.DBSignature{identityChainID, make([]byte, 32), make([]byte, 64)},
+		&factom.AddFederatedServer{identityChainID, 13},
+		&factom.RemoveFederatedServer{identityChainID, 14},
+		&factom.AddFederatedServerSigningKey{identityChainID, 0, make([]byte, 32), 13},
+		&factom.CoinbaseDescriptor{[]*factom.TransAddress{{6400000000, make([]byte, 32)}}},
+		&factom.UnknownAdminEntry{0x20, []byte{1, 2, 3}},
+		&factom.ServerFaultHandoff{1e12, identityChainID, identityChainID, 2, 15, 7,
+			[]*factom.ServerFaultSignature{{make([]byte, 32), make([]byte, 64)}}},
+		&factom.CoinbaseDescriptorCancel{10, 1},
+	}
+
+	p, err := a.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a2 := new(factom.ABlock)
+	if err := a2.UnmarshalBinary(p); err != nil {
+		t.Fatal(err)
+	}
+	if len(a2.Entries) != len(a.Entries) {
+		t.Fatalf("Admin Block did not round trip\n%s", a2)
+	}
+	if s, ok := a2.Entries[1].(*factom.AddFederatedServer); !ok ||
+		s.IdentityChainID != identityChainID || s.DBHeight != 13 {
+		t.Errorf("wrong AddFederatedServer %+v", a2.Entries[1])
+	}
+	if c, ok := a2.Entries[4].(*factom.CoinbaseDescriptor); !ok ||
+		c.Outputs[0].Amount != 6400000000 {
+		t.Errorf("wrong CoinbaseDescriptor %+v", a2.Entries[4])
+	}
+	if f, ok := a2.Entries[6].(*factom.ServerFaultHandoff); !ok ||
+		f.MilliTimestamp != 1e12 || f.AuditServerID != identityChainID ||
+		f.VMIndex != 2 || f.Height != 7 || len(f.Signatures) != 1 {
+		t.Errorf("wrong ServerFaultHandoff %+v", a2.Entries[6])
+	}
+	if c, ok := a2.Entries[7].(*factom.CoinbaseDescriptorCancel); !ok || c.DescriptorIndex != 1 {
+		t.Errorf("wrong entry after a ServerFaultHandoff %+v", a2.Entries[7])
+	}
+	t.Log(a2)
+}
@@ -0,0 +1,112 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	fctPubPrefix = []byte{0x5f, 0xb1}
+	ecPubPrefix  = []byte{0x59, 0x2a}
+)
+
+// FctAddressString returns the human readable "FA..." form of a 32 byte
+// Factoid address (the RCD hash).
+func FctAddressString(rcdHash []byte) string {
+	return addressString(fctPubPrefix, rcdHash)
+}
+
+// ECAddressString returns the human readable "EC..." form of a 32 byte Entry
+// Credit public key.
+func ECAddressString(pub []byte) string {
+	return addressString(ecPubPrefix, pub)
+}
+
+// ParseFctAddress returns the 32 byte RCD hash of a human readable Factoid
+// address.
+func ParseFctAddress(s string) ([]byte, error) {
+	return parseAddress(fctPubPrefix, s)
+}
+
+// ParseECAddress returns the 32 byte public key of a human readable Entry
+// Credit address.
+func ParseECAddress(s string) ([]byte, error) {
+	return parseAddress(ecPubPrefix, s)
+}
+
+// addressString encodes prefix + key + checksum in base58. The checksum is the
+// first 4 bytes of shad(prefix + key).
+func addressString(prefix, key []byte) string {
+	p := append(append([]byte{}, prefix...), key...)
+	p = append(p, shad(p)[:4]...)
+	return base58Encode(p)
+}
+
+func parseAddress(prefix []byte, s string) ([]byte, error) {
+	p, err := base58Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(p) != 38 {
+		return nil, fmt.Errorf("Invalid address length %s", s)
+	}
+	if !bytes.Equal(p[:2], prefix) {
+		return nil, fmt.Errorf("Invalid address prefix %s", s)
+	}
+	if !bytes.Equal(p[34:], shad(p[:34])[:4]) {
+		return nil, fmt.Errorf("Invalid address checksum %s", s)
+	}
+	return p[2:34], nil
+}
+
+func base58Encode(p []byte) string {
+	n := new(big.Int).SetBytes(p)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	var s []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		s = append(s, base58Alphabet[mod.Int64()])
+	}
+	for _, b := range p {
+		if b != 0 {
+			break
+		}
+		s = append(s, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+	return string(s)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, c := range s {
+		i := bytes.IndexRune([]byte(base58Alphabet), c)
+		if i < 0 {
+			return nil, fmt.Errorf("Invalid base58 character %q", c)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(i)))
+	}
+
+	p := n.Bytes()
+	for _, c := range s {
+		if c != rune(base58Alphabet[0]) {
+			break
+		}
+		p = append([]byte{0}, p...)
+	}
+	return p, nil
+}
@@ -0,0 +1,321 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// A Chain archive is a file of JSON lines. The first line describes the Chain,
+// and it is followed, for every Entry Block from the first to the head, by a
+// line for each of its Entries and then a line for the Entry Block
+//
+//	{"Type":"chain","ChainID":"...","Head":"...","EBlocks":2}
+//	{"Type":"entry","Hash":"...","Entry":"<hex binary Entry>"}
+//	{"Type":"eblock","KeyMR":"...","DBHeight":10,"DBKeyMR":"...","EBlock":{...},"Raw":"<hex binary Entry Block>"}
+//
+// VerifyArchive checks an archive without the network.
+const (
+	archiveChain  = "chain"
+	archiveEntry  = "entry"
+	archiveEBlock = "eblock"
+)
+
+type archiveRecord struct {
+	Type     string
+	ChainID  string  `json:",omitempty"`
+	Head     string  `json:",omitempty"`
+	EBlocks  int     `json:",omitempty"`
+	Hash     string  `json:",omitempty"`
+	Entry    string  `json:",omitempty"`
+	KeyMR    string  `json:",omitempty"`
+	DBHeight int     `json:",omitempty"`
+	DBKeyMR  string  `json:",omitempty"`
+	EBlock   *EBlock `json:",omitempty"`
+	Raw      string  `json:",omitempty"`
+}
+
+// ArchiveSummary describes a verified Chain archive.
+type ArchiveSummary struct {
+	ChainID string
+	Head    string
+	EBlocks int
+	Entries int
+}
+
+// archiveBlock is an Entry Block read from an archive with its Entries.
+type archiveBlock struct {
+	keymr    string
+	dbheight int
+	eb       *EBlock
+	entries  []*Entry
+}
+
+// ExportChain writes an archive of every Entry Block, in JSON and binary, and
+// Entry in the Chain, with the Directory Block holding each Entry Block, to w.
+func ExportChain(w io.Writer, chainid string) error {
+	head, err := GetChainHead(chainid)
+	if err != nil {
+		return err
+	}
+
+	keymrs := make([]string, 0)
+	ebs := make([]*EBlock, 0)
+	for keymr := head.ChainHead; keymr != ZeroHash; {
+		eb, err := GetEBlock(keymr)
+		if err != nil {
+			return err
+		}
+		keymrs = append(keymrs, keymr)
+		ebs = append(ebs, eb)
+		keymr = eb.Header.PrevKeyMR
+	}
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(&archiveRecord{
+		Type:    archiveChain,
+		ChainID: chainid,
+		Head:    head.ChainHead,
+		EBlocks: len(ebs),
+	}); err != nil {
+		return err
+	}
+
+	for i := len(ebs) - 1; i >= 0; i-- {
+		for _, v := range ebs[i].EntryList {
+			e, err := GetEntry(v.EntryHash)
+			if err != nil {
+				return err
+			}
+			p, err := e.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			if err := enc.Encode(&archiveRecord{
+				Type:  archiveEntry,
+				Hash:  v.EntryHash,
+				Entry: hex.EncodeToString(p),
+			}); err != nil {
+				return err
+			}
+		}
+
+		h, err := GetEBlockDBHeight(keymrs[i])
+		if err != nil {
+			return err
+		}
+		d, err := GetDBlockByHeight(h)
+		if err != nil {
+			return err
+		}
+		raw, err := GetRaw(keymrs[i])
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(&archiveRecord{
+			Type:     archiveEBlock,
+			KeyMR:    keymrs[i],
+			DBHeight: h,
+			DBKeyMR:  d.KeyMR,
+			EBlock:   ebs[i],
+			Raw:      hex.EncodeToString(raw),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyArchive reads the archive and checks, without the network, that every
+// Entry matches its hash and belongs to the Chain, that the KeyMR of every
+// Entry Block recomputed from its binary form matches, that every Entry Block
+// lists exactly the Entries before it, and that the Entry Blocks link by KeyMR
+// from the start of the Chain to the head in sequence and in rising Directory
+// Blocks. The Directory Block KeyMRs and the timestamps are kept for reference
+// but cannot be checked without the Directory Blocks.
+func VerifyArchive(r io.Reader) (*ArchiveSummary, error) {
+	s, _, err := readArchive(r)
+	return s, err
+}
+
+// Import verifies the archive and adds its Chain to the Mirror, so that the
+// Chain is served locally and later syncs continue from the archive head.
+func (m *Mirror) Import(r io.Reader) (*ArchiveSummary, error) {
+	s, blocks, err := readArchive(r)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	head, ok := m.heads[s.ChainID]
+	m.mu.RUnlock()
+
+	// only the blocks after the mirrored head are added
+	start := 0
+	if ok && head != "" {
+		start = -1
+		for i, b := range blocks {
+			if b.keymr == head {
+				start = i + 1
+			}
+		}
+		if start < 0 {
+			return nil, fmt.Errorf("Mirrored head %s of Chain %s is not in the archive",
+				head, s.ChainID)
+		}
+	}
+
+	for _, b := range blocks[start:] {
+		if err := m.putEBlock(s.ChainID, b.keymr, b.eb, b.dbheight, b.entries); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// readArchive reads and verifies the archive.
+func readArchive(r io.Reader) (*ArchiveSummary, []*archiveBlock, error) {
+	// the records are decoded from the stream rather than scanned by line,
+	// since the eblock record of a large Entry Block has no useful bound
+	d := json.NewDecoder(r)
+
+	line := 0
+	next := func() (*archiveRecord, error) {
+		rec := new(archiveRecord)
+		if err := d.Decode(rec); err != nil {
+			if err == io.EOF {
+				return nil, err
+			}
+			return nil, fmt.Errorf("Archive line %d: %s", line+1, err)
+		}
+		line++
+		return rec, nil
+	}
+
+	rec, err := next()
+	if err == io.EOF || (err == nil && rec.Type != archiveChain) {
+		return nil, nil, fmt.Errorf("Archive does not start with a chain record")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	s := &ArchiveSummary{ChainID: rec.ChainID, Head: rec.Head}
+	want := rec.EBlocks
+
+	blocks := make([]*archiveBlock, 0)
+	b := new(archiveBlock)
+	prev, prevHeight := ZeroHash, -1
+	for {
+		rec, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch rec.Type {
+		case archiveEntry:
+			p, err := hex.DecodeString(rec.Entry)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Archive line %d: %s", line, err)
+			}
+			e := NewEntry()
+			if err := e.UnmarshalBinary(p); err != nil {
+				return nil, nil, fmt.Errorf("Archive line %d: %s", line, err)
+			}
+			if h := hex.EncodeToString(e.Hash()); h != rec.Hash {
+				return nil, nil, fmt.Errorf("Archive line %d: Entry hash is %s not %s",
+					line, h, rec.Hash)
+			}
+			if e.ChainID != s.ChainID {
+				return nil, nil, fmt.Errorf("Archive line %d: Entry is in Chain %s",
+					line, e.ChainID)
+			}
+			b.entries = append(b.entries, e)
+
+		case archiveEBlock:
+			eb := rec.EBlock
+			if eb == nil || rec.Raw == "" {
+				return nil, nil, fmt.Errorf("Archive line %d: eblock record without an Entry Block", line)
+			}
+			p, err := hex.DecodeString(rec.Raw)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Archive line %d: %s", line, err)
+			}
+			raw, err := decodeRawEBlock(p)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Archive line %d: %s", line, err)
+			}
+			if raw.KeyMR != rec.KeyMR {
+				return nil, nil, fmt.Errorf("Archive line %d: Entry Block KeyMR is %s not %s",
+					line, raw.KeyMR, rec.KeyMR)
+			}
+			if raw.ChainID != s.ChainID || raw.PrevKeyMR != eb.Header.PrevKeyMR ||
+				raw.Sequence != eb.Header.BlockSequenceNumber || raw.DBHeight != rec.DBHeight ||
+				len(raw.EntryHashes) != len(eb.EntryList) {
+				return nil, nil, fmt.Errorf("Archive line %d: Entry Block %s does not match its binary form",
+					line, rec.KeyMR)
+			}
+			if eb.Header.PrevKeyMR != prev {
+				return nil, nil, fmt.Errorf("Archive line %d: Entry Block %s follows %s not %s",
+					line, rec.KeyMR, eb.Header.PrevKeyMR, prev)
+			}
+			if eb.Header.BlockSequenceNumber != len(blocks) {
+				return nil, nil, fmt.Errorf("Archive line %d: Entry Block %s is number %d not %d",
+					line, rec.KeyMR, eb.Header.BlockSequenceNumber, len(blocks))
+			}
+			if id := eb.Header.ChainID; id != "" && id != s.ChainID {
+				return nil, nil, fmt.Errorf("Archive line %d: Entry Block %s is in Chain %s",
+					line, rec.KeyMR, id)
+			}
+			if rec.DBHeight <= prevHeight {
+				return nil, nil, fmt.Errorf("Archive line %d: Entry Block %s is at height %d after %d",
+					line, rec.KeyMR, rec.DBHeight, prevHeight)
+			}
+			if len(eb.EntryList) != len(b.entries) {
+				return nil, nil, fmt.Errorf("Archive line %d: Entry Block %s lists %d Entries not %d",
+					line, rec.KeyMR, len(eb.EntryList), len(b.entries))
+			}
+			for i, v := range eb.EntryList {
+				if h := hex.EncodeToString(b.entries[i].Hash()); h != v.EntryHash || h != raw.EntryHashes[i] {
+					return nil, nil, fmt.Errorf("Archive line %d: Entry Block %s lists %s not %s",
+						line, rec.KeyMR, v.EntryHash, h)
+				}
+			}
+
+			b.keymr, b.dbheight, b.eb = rec.KeyMR, rec.DBHeight, eb
+			blocks = append(blocks, b)
+			s.Entries += len(b.entries)
+			prev, prevHeight = rec.KeyMR, rec.DBHeight
+			b = new(archiveBlock)
+
+		default:
+			return nil, nil, fmt.Errorf("Archive line %d: unknown record type %s", line, rec.Type)
+		}
+	}
+
+	if len(b.entries) > 0 {
+		return nil, nil, fmt.Errorf("Archive ends with Entries outside an Entry Block")
+	}
+	if prev != s.Head || len(blocks) != want {
+		return nil, nil, fmt.Errorf("Archive has %d Entry Blocks to %s not %d to %s",
+			len(blocks), prev, want, s.Head)
+	}
+	s.EBlocks = len(blocks)
+	return s, blocks, nil
+}
+
+func (s *ArchiveSummary) String() string {
+	var str string
+	str += fmt.Sprintln("ChainID:", s.ChainID)
+	str += fmt.Sprintln("Head:", s.Head)
+	str += fmt.Sprintln("EBlocks:", s.EBlocks)
+	str += fmt.Sprintln("Entries:", s.Entries)
+	return str
+}
@@ -0,0 +1,100 @@
+package factom_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestArchive(t *testing.T) {
+	c := newTestChain(t)
+	c.add("a", "b")
+	c.tick()
+	c.add()
+	c.add("c")
+
+	buf := new(bytes.Buffer)
+	if err := factom.ExportChain(buf, c.ChainID); err != nil {
+		t.Fatal(err)
+	}
+	archive := buf.String()
+
+	s, err := factom.VerifyArchive(strings.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.EBlocks != 3 || s.Entries != 3 || s.Head != c.KeyMRs[2] {
+		t.Errorf("wrong summary\n%s", s)
+	}
+
+	lines := strings.SplitAfter(archive, "\n")
+	for name, bad := range map[string]string{
+		// "a" is 61 in hex
+		"changed Entry":   strings.Replace(archive, `61"}`, `62"}`, 1),
+		"missing EBlock":  strings.Join(append(append([]string{}, lines[:4]...), lines[5:]...), ""),
+		"truncated":       strings.Join(lines[:len(lines)-2], ""),
+		"missing header":  strings.Join(lines[1:], ""),
+		"reordered Entry": strings.Join(append([]string{lines[0], lines[2], lines[1]}, lines[3:]...), ""),
+		"changed height":  strings.Replace(archive, `"DBHeight":2,`, `"DBHeight":3,`, 1),
+		"forged KeyMR":    strings.ReplaceAll(archive, c.KeyMRs[2], strings.Repeat("1", 64)),
+	} {
+		if _, err := factom.VerifyArchive(strings.NewReader(bad)); err == nil {
+			t.Errorf("%s archive was verified", name)
+		}
+	}
+
+	m, err := factom.OpenMirror(filepath.Join(t.TempDir(), "mirror"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if _, err := m.Import(strings.NewReader(archive)); err != nil {
+		t.Fatal(err)
+	}
+
+	gets := c.Gets()
+	if s := contents(t, m.NewChainIterator(c.ChainID), -1); s != "abc" {
+		t.Errorf("iterated %q from the imported archive", s)
+	}
+	if n := c.Gets() - gets; n != 0 {
+		t.Errorf("%d Entries fetched from the Network", n)
+	}
+
+	// the imported Chain syncs from the archive head
+	c.add("d")
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := c.Gets() - gets; n != 1 {
+		t.Errorf("%d Entries fetched for 1 new Entry", n)
+	}
+}
+
+func TestArchiveLargeEBlock(t *testing.T) {
+	c := newTestChain(t)
+	contents := make([]string, 7000)
+	for i := range contents {
+		contents[i] = "x"
+	}
+	c.add(contents...)
+
+	buf := new(bytes.Buffer)
+	if err := factom.ExportChain(buf, c.ChainID); err != nil {
+		t.Fatal(err)
+	}
+	// the eblock record is longer than a line the archive could once hold
+	if n := len(strings.SplitAfter(buf.String(), "\n")[len(contents)+1]); n <= 1<<20 {
+		t.Fatalf("eblock record of %d bytes", n)
+	}
+
+	s, err := factom.VerifyArchive(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.EBlocks != 1 || s.Entries != len(contents) {
+		t.Errorf("wrong summary\n%s", s)
+	}
+}
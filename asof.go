@@ -0,0 +1,94 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ChainHeadAt returns the head the Chain had at the Directory Block height,
+// the latest Entry Block recorded at or below it. It walks back from the
+// current head looking up the height of each Entry Block. The error wraps
+// ErrChainNotFound if the Chain did not exist at the height.
+func ChainHeadAt(chainid string, height int) (*ChainHead, error) {
+	return chainHeadAt(Network, chainid, height)
+}
+
+func chainHeadAt(src Source, chainid string, height int) (*ChainHead, error) {
+	head, err := src.GetChainHead(chainid)
+	if err != nil {
+		return nil, err
+	}
+
+	hs, _ := src.(dbHeighter)
+	for keymr := head.ChainHead; keymr != ZeroHash; {
+		h, ok := 0, false
+		if hs != nil {
+			h, ok = hs.EBlockDBHeight(keymr)
+		}
+		if !ok {
+			if h, err = GetEBlockDBHeight(keymr); err != nil {
+				return nil, err
+			}
+		}
+		if h <= height {
+			return &ChainHead{keymr}, nil
+		}
+
+		eb, err := src.GetEBlock(keymr)
+		if err != nil {
+			return nil, err
+		}
+		keymr = eb.Header.PrevKeyMR
+	}
+	return nil, fmt.Errorf("%w: %s did not exist at height %d", ErrChainNotFound, chainid, height)
+}
+
+// ChainHeadAt updates the index of the Chain and returns the head the Chain
+// had at the Directory Block height. The Entry Block is found by binary search
+// on the index for the time of the Directory Block, which is fetched through
+// DBlocks if it is set.
+func (ix *EBlockIndex) ChainHeadAt(chainid string, height int) (*ChainHead, error) {
+	refs, err := ix.Update(chainid)
+	if err != nil {
+		return nil, err
+	}
+	d, err := ix.DBlocks.GetDBlock(height)
+	if err != nil {
+		return nil, err
+	}
+	t := int64(d.Header.Timestamp)
+
+	// Entry Blocks take the timestamp of the Directory Block holding them
+	i := sort.Search(len(refs), func(i int) bool {
+		return refs[i].Timestamp.Unix() > t
+	})
+	if i == 0 {
+		return nil, fmt.Errorf("%w: %s did not exist at height %d", ErrChainNotFound, chainid, height)
+	}
+	return &ChainHead{refs[i-1].KeyMR}, nil
+}
+
+// AsOf returns an iterator over the Entries of the Chain, oldest first, as the
+// Chain was at the Directory Block height, finding the head it had then from
+// the index.
+func (ix *EBlockIndex) AsOf(chainid string, height int) (*ChainIterator, error) {
+	head, err := ix.ChainHeadAt(chainid, height)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := ix.EBlocks(chainid)
+	it := NewChainIterator(chainid).UseSource(ix.Source)
+	it.started = true
+	it.blocks = make([]*chainBlock, 0)
+	for k := len(refs) - 1; k >= 0; k-- {
+		if len(it.blocks) > 0 || refs[k].KeyMR == head.ChainHead {
+			it.blocks = append(it.blocks, &chainBlock{keymr: refs[k].KeyMR})
+		}
+	}
+	return it, nil
+}
@@ -0,0 +1,80 @@
+package factom_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestChainHeadAt(t *testing.T) {
+	c := newTestChain(t)
+	c.tick()
+	c.add("a")
+	c.tick()
+	c.tick()
+	c.add("b", "c")
+	c.tick()
+	c.add("d")
+
+	m, err := factom.OpenMirror(filepath.Join(t.TempDir(), "mirror"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	m.Add(c.ChainID)
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	ix := factom.NewEBlockIndex()
+	ix.DBlocks = factom.NewDBlockIndex()
+
+	// Entry Blocks are at heights 1, 4 and 6
+	for _, v := range []struct {
+		height int
+		head   int
+		want   string
+	}{
+		{0, -1, ""},
+		{1, 0, "a"},
+		{3, 0, "a"},
+		{4, 1, "abc"},
+		{5, 1, "abc"},
+		{6, 2, "abcd"},
+	} {
+		h1, err1 := factom.ChainHeadAt(c.ChainID, v.height)
+		h2, err2 := ix.ChainHeadAt(c.ChainID, v.height)
+		if v.head < 0 {
+			if !errors.Is(err1, factom.ErrChainNotFound) || !errors.Is(err2, factom.ErrChainNotFound) {
+				t.Errorf("Chain existed at height %d: %v %v", v.height, err1, err2)
+			}
+			continue
+		}
+		if err1 != nil || err2 != nil {
+			t.Fatalf("height %d: %v %v", v.height, err1, err2)
+		}
+		if h1.ChainHead != c.KeyMRs[v.head] || h2.ChainHead != c.KeyMRs[v.head] {
+			t.Errorf("wrong head at height %d: %s %s", v.height, h1.ChainHead, h2.ChainHead)
+		}
+
+		if s := contents(t, factom.NewChainIterator(c.ChainID).AsOf(v.height), -1); s != v.want {
+			t.Errorf("iterated %q at height %d", s, v.height)
+		}
+		if s := contents(t, m.NewReverseChainIterator(c.ChainID).AsOf(v.height), 1); s != v.want[len(v.want)-1:] {
+			t.Errorf("latest Entry %q at height %d", s, v.height)
+		}
+		it, err := ix.AsOf(c.ChainID, v.height)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := contents(t, it, -1); s != v.want {
+			t.Errorf("iterated %q from the index at height %d", s, v.height)
+		}
+	}
+
+	if h, err := factom.ChainHeadAt(c.ChainID, 100); err != nil || h.ChainHead != c.KeyMRs[2] {
+		t.Errorf("wrong head above the latest height: %v %v", h, err)
+	}
+}
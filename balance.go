@@ -47,7 +47,9 @@ func ECBalance(key string) (int64, error) {
 	return v, nil
 }
 
-func FctBalance(key string) (int64, error) {
+// FctBalance returns the Factoid balance of the wallet address name or the
+// human readable Factoid address.
+func FctBalance(key string) (Factoshi, error) {
 	str := fmt.Sprintf("http://%s/v1/factoid-balance/%s", serverFct, key)
 	resp, err := http.Get(str)
 	if err != nil {
@@ -78,17 +80,19 @@ func FctBalance(key string) (int64, error) {
 		return 0, fmt.Errorf("Error getting the balance of %s", key)
 	}
 
-	return v, nil
+	return Factoshi(v), nil
 }
 
-func DnsBalance(addr string) (int64, int64, error) {
-	fct, ec, err := ResolveDnsName(addr)
+// DnsBalance returns the Factoid balance and the Entry Credit balance of the
+// addresses the DNS name resolves to.
+func DnsBalance(addr string) (fct Factoshi, ec int64, err error) {
+	fctAddr, ecAddr, err := ResolveDnsName(addr)
 	if err != nil {
 		return 0, 0, err
 	}
 
-	f, err1 := FctBalance(fct)
-	e, err2 := ECBalance(ec)
+	f, err1 := FctBalance(fctAddr)
+	e, err2 := ECBalance(ecAddr)
 	if err1 != nil || err2 != nil {
 		return f, e, fmt.Errorf("%s\n%s\n", err1, err2)
 	}
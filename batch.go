@@ -0,0 +1,215 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrBatchClosed    = errors.New("BatchWriter is closed")
+	ErrInsufficientEC = errors.New("Insufficient Entry Credit balance")
+)
+
+// BatchResult is the outcome of one Entry written by a BatchWriter. Result is
+// nil if the commit failed.
+type BatchResult struct {
+	Entry  *Entry
+	Result *WriteResult
+	Err    error
+}
+
+// BatchWriter writes a stream of Entries paid for by one wallet Entry Credit
+// address. Commits are sent in order at no more than the rate per second,
+// after checking the Entry Credit balance covers them, while up to the
+// concurrency of reveals run in parallel.
+//
+// The result of every Entry is sent on Results, which must be read until it is
+// closed by Close.
+type BatchWriter struct {
+	Writer *Writer
+
+	rate int
+
+	queue   chan *Entry
+	reveals chan *batchReveal
+	results chan *BatchResult
+
+	// balance is the Entry Credits known to be available for commits
+	balance int64
+
+	// err is the first error of a reveal, returned by Close
+	errMu sync.Mutex
+	err   error
+
+	// mu guards closing the queue against Writes sending on it
+	mu       sync.RWMutex
+	closed   bool
+	revealed sync.WaitGroup
+	finished chan struct{}
+}
+
+type batchReveal struct {
+	entry   *Entry
+	pending *pendingWrite
+}
+
+// NewBatchWriter starts a BatchWriter paying with the wallet Entry Credit
+// address name. A rate of 0, or one above a commit per nanosecond, sends
+// commits as fast as possible.
+func NewBatchWriter(name string, concurrency, rate int) *BatchWriter {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if rate > int(time.Second) {
+		rate = 0
+	}
+
+	b := new(BatchWriter)
+	b.Writer = NewWriter(name)
+	b.rate = rate
+	b.balance = -1
+	b.queue = make(chan *Entry, concurrency)
+	b.reveals = make(chan *batchReveal, concurrency)
+	b.results = make(chan *BatchResult, concurrency)
+	b.finished = make(chan struct{})
+
+	go b.commitLoop()
+	b.revealed.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go b.revealLoop()
+	}
+	go func() {
+		b.revealed.Wait()
+		close(b.results)
+		close(b.finished)
+	}()
+
+	return b
+}
+
+// Write queues the Entry to be written. It blocks while the queue is full.
+func (b *BatchWriter) Write(e *Entry) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return ErrBatchClosed
+	}
+	b.queue <- e
+	return nil
+}
+
+// Results returns the channel of per Entry results.
+func (b *BatchWriter) Results() <-chan *BatchResult {
+	return b.results
+}
+
+// Close stops accepting Entries, waits for every queued Entry to be committed
+// and revealed, and then closes Results. It returns the first reveal error,
+// which is also in the result of its Entry.
+func (b *BatchWriter) Close() error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+
+	<-b.finished
+
+	b.errMu.Lock()
+	defer b.errMu.Unlock()
+	return b.err
+}
+
+// commitLoop commits the queued Entries one at a time, in order, and hands
+// them to the reveal workers.
+func (b *BatchWriter) commitLoop() {
+	defer close(b.reveals)
+
+	var tick <-chan time.Time
+	if b.rate > 0 {
+		t := time.NewTicker(time.Second / time.Duration(b.rate))
+		defer t.Stop()
+		tick = t.C
+	}
+
+	for e := range b.queue {
+		if tick != nil {
+			<-tick
+		}
+
+		cost, err := b.spend(e)
+		if err != nil {
+			b.results <- &BatchResult{e, nil, err}
+			continue
+		}
+
+		p, err := b.Writer.commitEntry(e)
+		if err != nil {
+			// the balance is unknown after a failed commit
+			b.balance = -1
+			b.results <- &BatchResult{e, nil, err}
+			continue
+		}
+		if p.result.Status == WriteAlreadyExists {
+			// nothing was paid for
+			b.balance += cost
+			b.results <- &BatchResult{e, p.result, nil}
+			continue
+		}
+		b.reveals <- &batchReveal{e, p}
+	}
+}
+
+// spend deducts the cost of the Entry from the known balance, fetching the
+// balance from the wallet when it is unknown. The wallet balance is not
+// refetched while it covers the commits, since it may not yet reflect the
+// commits already sent, but it is once it runs short, so a topped up address
+// is used again.
+func (b *BatchWriter) spend(e *Entry) (int64, error) {
+	c, err := entryCost(e)
+	if err != nil {
+		return 0, err
+	}
+	cost := int64(c)
+
+	if b.balance < 0 {
+		bal, err := ECBalance(b.Writer.Name)
+		if err != nil {
+			return 0, err
+		}
+		b.balance = bal
+	}
+	if b.balance < cost {
+		err := fmt.Errorf("%w: %d EC needed, %d available",
+			ErrInsufficientEC, cost, b.balance)
+		b.balance = -1
+		return 0, err
+	}
+
+	b.balance -= cost
+	return cost, nil
+}
+
+func (b *BatchWriter) revealLoop() {
+	defer b.revealed.Done()
+
+	for r := range b.reveals {
+		res, err := b.Writer.reveal(r.pending)
+		if err != nil {
+			b.errMu.Lock()
+			if b.err == nil {
+				b.err = err
+			}
+			b.errMu.Unlock()
+		}
+		b.results <- &BatchResult{r.entry, res, err}
+	}
+}
@@ -0,0 +1,107 @@
+package factom_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestBatchWriter(t *testing.T) {
+	var mu sync.Mutex
+	balance := 3
+	newTestServer(t, map[string]http.HandlerFunc{
+		"/v1/entry-credit-balance/": func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			fmt.Fprintf(w, `{"Response":"%d","Success":true}`, balance)
+		},
+		"/v1/commit-entry/": func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			balance--
+		},
+		"/v1/reveal-entry/": nil,
+	})
+
+	b := factom.NewBatchWriter("app", 2, 1000)
+	write := func(i int) *factom.BatchResult {
+		e := factom.NewEntry()
+		e.ChainID = factom.ZeroHash
+		e.Content = []byte(fmt.Sprint("entry ", i))
+		if err := b.Write(e); err != nil {
+			t.Fatal(err)
+		}
+		return <-b.Results()
+	}
+
+	written, short := 0, 0
+	for i := 0; i < 5; i++ {
+		switch r := write(i); {
+		case r.Err == nil && r.Result.Status == factom.WriteRevealed:
+			written++
+		case errors.Is(r.Err, factom.ErrInsufficientEC):
+			short++
+		default:
+			t.Errorf("unexpected result %v %v", r.Result, r.Err)
+		}
+	}
+	if written != 3 || short != 2 {
+		t.Errorf("%d written and %d short of EC", written, short)
+	}
+
+	// the balance is fetched again once the address is topped up
+	mu.Lock()
+	balance = 10
+	mu.Unlock()
+	if r := write(5); r.Err != nil {
+		t.Errorf("Entry not written after the top up: %v", r.Err)
+	}
+
+	if err := b.Close(); err != nil {
+		t.Error(err)
+	}
+	if _, ok := <-b.Results(); ok {
+		t.Error("Results not closed")
+	}
+	if err := b.Write(factom.NewEntry()); err != factom.ErrBatchClosed {
+		t.Errorf("Write after Close returned %v", err)
+	}
+}
+
+func TestBatchWriterCloseError(t *testing.T) {
+	newTestServer(t, map[string]http.HandlerFunc{
+		"/v1/entry-credit-balance/": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"Response":"100","Success":true}`))
+		},
+		"/v1/commit-entry/":  nil,
+		"/v1/entry-by-hash/": entryNotFound,
+		"/v1/reveal-entry/": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "reveal refused", http.StatusBadRequest)
+		},
+		"/v2": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":{"commitdata":{"status":"TransactionACK"}}}`))
+		},
+	})
+
+	// a rate too high to pace is not a ticker panic
+	b := factom.NewBatchWriter("app", 1, int(time.Second)+1)
+	go func() {
+		e := factom.NewEntry()
+		e.ChainID = factom.ZeroHash
+		b.Write(e)
+		b.Close()
+	}()
+	for r := range b.Results() {
+		if r.Err == nil {
+			t.Error("refused reveal was written")
+		}
+	}
+	if err := b.Close(); err == nil {
+		t.Error("Close did not return the reveal error")
+	}
+}
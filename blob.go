@@ -0,0 +1,223 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+// A Blob is stored as a chunk Entry for every BlobChunkSize bytes of data and
+// a manifest Entry. The ExtIDs of a chunk are
+//
+//	"blob", blob ID, 4 byte index, 4 byte total chunks, sha256 of the chunk
+//
+// and the ExtIDs of the manifest are
+//
+//	"blob-manifest", blob ID, 4 byte total chunks, 8 byte size
+//
+// where the blob ID is the sha256 of the whole Blob. All integers are big
+// endian.
+const (
+	BlobChunkSize = 10112
+
+	blobChunkTag    = "blob"
+	blobManifestTag = "blob-manifest"
+)
+
+// Blob is data too large for a single Entry split into chunk Entries.
+type Blob struct {
+	ID       []byte
+	Size     int64
+	Chunks   []*Entry
+	Manifest *Entry
+}
+
+// NewBlob splits the data into the chunk and manifest Entries of a Blob in the
+// Chain.
+func NewBlob(chainid string, data []byte) *Blob {
+	id := sha256.Sum256(data)
+
+	b := new(Blob)
+	b.ID = id[:]
+	b.Size = int64(len(data))
+
+	total := (len(data) + BlobChunkSize - 1) / BlobChunkSize
+	for i := 0; i < total; i++ {
+		end := (i + 1) * BlobChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := data[i*BlobChunkSize : end]
+		h := sha256.Sum256(chunk)
+
+		e := NewEntry()
+		e.ChainID = chainid
+		e.ExtIDs = [][]byte{
+			[]byte(blobChunkTag),
+			b.ID,
+			uint32Bytes(uint32(i)),
+			uint32Bytes(uint32(total)),
+			h[:],
+		}
+		e.Content = chunk
+		b.Chunks = append(b.Chunks, e)
+	}
+
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(b.Size))
+	b.Manifest = NewEntry()
+	b.Manifest.ChainID = chainid
+	b.Manifest.ExtIDs = [][]byte{
+		[]byte(blobManifestTag),
+		b.ID,
+		uint32Bytes(uint32(total)),
+		size,
+	}
+
+	return b
+}
+
+// Entries returns the chunk Entries followed by the manifest Entry.
+func (b *Blob) Entries() []*Entry {
+	return append(append([]*Entry{}, b.Chunks...), b.Manifest)
+}
+
+// Cost returns the number of Entry Credits needed to commit every Entry of the
+// Blob. QuoteEntries prices them in factoshis.
+func (b *Blob) Cost() (int64, error) {
+	var ec int64
+	for _, e := range b.Entries() {
+		c, err := entryCost(e)
+		if err != nil {
+			return 0, err
+		}
+		ec += int64(c)
+	}
+	return ec, nil
+}
+
+// WriteBlob commits and reveals the chunks of the Blob, in order, and then its
+// manifest. It stops at the first failed write and returns the results so far.
+func (w *Writer) WriteBlob(b *Blob) ([]*WriteResult, error) {
+	rs := make([]*WriteResult, 0)
+	for _, e := range b.Entries() {
+		r, err := w.WriteEntry(e)
+		if r != nil {
+			rs = append(rs, r)
+		}
+		if err != nil {
+			return rs, err
+		}
+	}
+	return rs, nil
+}
+
+// ReadBlob finds the manifest and chunks of the Blob with the ID in the Chain,
+// and returns the reassembled data after checking every chunk hash and the
+// hash of the whole Blob.
+func ReadBlob(chainid string, id []byte) ([]byte, error) {
+	es, err := GetAllChainEntries(chainid)
+	if err != nil {
+		return nil, err
+	}
+	return readBlob(es, id)
+}
+
+func readBlob(es []*Entry, id []byte) ([]byte, error) {
+	type manifest struct {
+		total uint32
+		size  uint64
+	}
+	manifests := make([]manifest, 0)
+	chunks := make(map[uint32][]byte)
+
+	for _, e := range es {
+		ids := e.ExtIDs
+		if len(ids) < 2 || !bytes.Equal(ids[1], id) {
+			continue
+		}
+		switch string(ids[0]) {
+		case blobManifestTag:
+			if len(ids) != 4 || len(ids[2]) != 4 || len(ids[3]) != 8 {
+				continue
+			}
+			manifests = append(manifests, manifest{
+				binary.BigEndian.Uint32(ids[2]),
+				binary.BigEndian.Uint64(ids[3]),
+			})
+		case blobChunkTag:
+			if len(ids) != 5 || len(ids[2]) != 4 || len(ids[3]) != 4 {
+				continue
+			}
+			// the first chunk at an index matching its hash is kept
+			i := binary.BigEndian.Uint32(ids[2])
+			h := sha256.Sum256(e.Content)
+			if _, ok := chunks[i]; !ok && bytes.Equal(h[:], ids[4]) {
+				chunks[i] = e.Content
+			}
+		}
+	}
+
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("Blob %x manifest not found", id)
+	}
+
+	// anyone can write a manifest for the ID, so each is tried in turn until
+	// the chunks it names reassemble to the ID
+	var err error
+	for _, m := range manifests {
+		var data []byte
+		if data, err = assembleBlob(chunks, id, m.total, m.size); err == nil {
+			return data, nil
+		}
+	}
+	return nil, err
+}
+
+// assembleBlob joins the chunks of a manifest and checks the whole Blob. The
+// data is sized from the chunks present, never from the manifest.
+func assembleBlob(chunks map[uint32][]byte, id []byte, total uint32, size uint64) ([]byte, error) {
+	if uint64(total) > uint64(len(chunks)) {
+		return nil, fmt.Errorf("Blob %x is missing chunks of %d", id, total)
+	}
+	var n uint64
+	for i := uint32(0); i < total; i++ {
+		c, ok := chunks[i]
+		if !ok {
+			return nil, fmt.Errorf("Blob %x is missing chunk %d of %d", id, i, total)
+		}
+		n += uint64(len(c))
+	}
+	if n != size {
+		return nil, fmt.Errorf("Blob %x chunks hold %d bytes not %d", id, n, size)
+	}
+
+	data := make([]byte, 0, n)
+	for i := uint32(0); i < total; i++ {
+		data = append(data, chunks[i]...)
+	}
+	if h := sha256.Sum256(data); !bytes.Equal(h[:], id) {
+		return nil, fmt.Errorf("Blob %x does not match its hash", id)
+	}
+	return data, nil
+}
+
+func (b *Blob) String() string {
+	var s string
+	s += fmt.Sprintln("ID:", hex.EncodeToString(b.ID))
+	s += fmt.Sprintln("Size:", b.Size)
+	s += fmt.Sprintln("Chunks:", len(b.Chunks))
+	return s
+}
+
+func uint32Bytes(i uint32) []byte {
+	p := make([]byte, 4)
+	binary.BigEndian.PutUint32(p, i)
+	return p
+}
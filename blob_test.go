@@ -0,0 +1,84 @@
+package factom_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestBlob(t *testing.T) {
+	c := newTestChain(t)
+
+	data := bytes.Repeat([]byte("0123456789"), 2500)
+	b := factom.NewBlob(c.ChainID, data)
+	if len(b.Chunks) != 3 {
+		t.Fatalf("%d chunks for %d bytes", len(b.Chunks), len(data))
+	}
+
+	// every chunk fits in a 10KB Entry and costs 10 EC, the manifest 1 EC
+	ec, err := b.Cost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ec != 10+10+5+1 {
+		t.Errorf("Blob costs %d EC", ec)
+	}
+
+	c.add("unrelated")
+	for _, e := range b.Entries() {
+		c.addEntries(e)
+	}
+
+	p, err := factom.ReadBlob(c.ChainID, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(p, data) {
+		t.Error("Blob was not reassembled")
+	}
+
+	// a forged chunk does not replace the first valid chunk
+	forged := factom.NewBlob(c.ChainID, bytes.Repeat([]byte("x"), len(data)))
+	forged.Chunks[1].ExtIDs[1] = b.ID
+	c.addEntries(forged.Chunks[1])
+	if p, err := factom.ReadBlob(c.ChainID, b.ID); err != nil || !bytes.Equal(p, data) {
+		t.Errorf("forged chunk changed the Blob: %v", err)
+	}
+
+	// later manifests that do not match the chunks are skipped
+	m := *b.Manifest
+	m.ExtIDs = [][]byte{m.ExtIDs[0], b.ID, {0, 0, 0, 1}, {0, 0, 0, 0, 0, 0, 0, 1}}
+	huge := *b.Manifest
+	huge.ExtIDs = [][]byte{m.ExtIDs[0], b.ID, {0, 0, 0, 3}, {0x40, 0, 0, 0, 0, 0, 0, 0}}
+	c.addEntries(&m, &huge)
+	if p, err := factom.ReadBlob(c.ChainID, b.ID); err != nil || !bytes.Equal(p, data) {
+		t.Errorf("forged manifest changed the Blob: %v", err)
+	}
+
+	other := factom.NewBlob(c.ChainID, []byte("other"))
+	c.addEntries(other.Chunks...)
+	bad := *other.Manifest
+	bad.ExtIDs = [][]byte{m.ExtIDs[0], other.ID, {0, 0, 0, 1}, {0x40, 0, 0, 0, 0, 0, 0, 0}}
+	c.addEntries(&bad)
+	if _, err := factom.ReadBlob(c.ChainID, other.ID); err == nil {
+		t.Error("Blob read with a manifest too large for its chunks")
+	}
+
+	// forged manifests written before the real one neither block the read nor
+	// size the buffer
+	first := factom.NewBlob(c.ChainID, []byte("first"))
+	c.addEntries(first.Chunks...)
+	oversized := *first.Manifest
+	oversized.ExtIDs = [][]byte{m.ExtIDs[0], first.ID, {0xff, 0xff, 0xff, 0xff}, {0, 0, 0x24, 0x61, 0x4e, 0x0c, 0x00, 0x00}}
+	short := *first.Manifest
+	short.ExtIDs = [][]byte{m.ExtIDs[0], first.ID, {0, 0, 0, 1}, {0, 0, 0, 0, 0, 0, 0, 1}}
+	c.addEntries(&oversized, &short, first.Manifest)
+	if p, err := factom.ReadBlob(c.ChainID, first.ID); err != nil || string(p) != "first" {
+		t.Errorf("forged manifests blocked the Blob: %q %v", p, err)
+	}
+
+	if _, err := factom.ReadBlob(c.ChainID, make([]byte, 32)); err == nil {
+		t.Error("missing Blob was read")
+	}
+}
@@ -9,11 +9,10 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-
-	ed "github.com/FactomProject/ed25519"
 )
 
 type Chain struct {
@@ -41,75 +40,25 @@ type ChainHead struct {
 	ChainHead string
 }
 
+// ErrChainNotFound is returned, wrapped with the ChainID, when the network has
+// no Chain with the ChainID.
+var ErrChainNotFound = errors.New("Chain not found")
+
+// missingChainHead is the error factomd answers with, as a bad request, for a
+// Chain it does not have.
+const missingChainHead = "Missing Chain Head"
+
 // CommitChain sends the signed ChainID, the Entry Hash, and the Entry Credit
 // public key to the factom network. Once the payment is verified and the
 // network is commited to publishing the Chain it may be published by revealing
 // the First Entry in the Chain.
 func CommitChain(c *Chain, name string) error {
-	type walletcommit struct {
-		Message string
-	}
-
-	buf := new(bytes.Buffer)
-
-	// 1 byte version
-	buf.Write([]byte{0})
-
-	// 6 byte milliTimestamp
-	buf.Write(milliTime())
-
-	e := c.FirstEntry
-
-	// 32 byte ChainID Hash
-	if p, err := hex.DecodeString(c.ChainID); err != nil {
-		return err
-	} else {
-		// double sha256 hash of ChainID
-		buf.Write(shad(p))
-	}
-
-	// 32 byte Weld; sha256(sha256(EntryHash + ChainID))
-	if cid, err := hex.DecodeString(c.ChainID); err != nil {
-		return err
-	} else {
-		s := append(e.Hash(), cid...)
-		buf.Write(shad(s))
-	}
-
-	// 32 byte Entry Hash of the First Entry
-	buf.Write(e.Hash())
-
-	// 1 byte number of Entry Credits to pay
-	if d, err := entryCost(e); err != nil {
-		return err
-	} else {
-		buf.WriteByte(byte(d + 10))
-	}
-
-	com := new(walletcommit)
-	com.Message = hex.EncodeToString(buf.Bytes())
-	j, err := json.Marshal(com)
-	if err != nil {
-		return err
-	}
-	resp, err := http.Post(
-		fmt.Sprintf("http://%s/v1/commit-chain/%s", serverFct, name),
-		"application/json",
-		bytes.NewBuffer(j))
+	cc, err := NewChainCommit(c)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		p, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf(string(p))
-	}
-
-	return nil
+	return walletCommit("commit-chain", name, cc.MarshalUnsignedBinary())
 }
 
 func ComposeChainCommit(pub *[32]byte, pri *[64]byte, c *Chain) ([]byte, error) {
@@ -117,53 +66,21 @@ func ComposeChainCommit(pub *[32]byte, pri *[64]byte, c *Chain) ([]byte, error)
 		CommitChainMsg string
 	}
 
-	buf := new(bytes.Buffer)
-
-	// 1 byte version
-	buf.Write([]byte{0})
-
-	// 6 byte milliTimestamp
-	buf.Write(milliTime())
-
-	e := c.FirstEntry
-
-	// 32 byte ChainID Hash
-	if p, err := hex.DecodeString(c.ChainID); err != nil {
-		return nil, err
-	} else {
-		// double sha256 hash of ChainID
-		buf.Write(shad(p))
-	}
-
-	// 32 byte Weld; sha256(sha256(EntryHash + ChainID))
-	if cid, err := hex.DecodeString(c.ChainID); err != nil {
+	cc, err := NewChainCommit(c)
+	if err != nil {
 		return nil, err
-	} else {
-		s := append(e.Hash(), cid...)
-		buf.Write(shad(s))
 	}
 
-	// 32 byte Entry Hash of the First Entry
-	buf.Write(e.Hash())
+	// sign the commit
+	cc.Sign(pub, pri)
 
-	// 1 byte number of Entry Credits to pay
-	if d, err := entryCost(e); err != nil {
+	p, err := cc.MarshalBinary()
+	if err != nil {
 		return nil, err
-	} else {
-		buf.WriteByte(byte(d + 10))
 	}
 
-	// sign the commit
-	sig := ed.Sign(pri, buf.Bytes())
-
-	// 32 byte pubkey
-	buf.Write(pub[:])
-
-	// 64 byte Signature
-	buf.Write(sig[:])
-
 	com := new(commit)
-	com.CommitChainMsg = hex.EncodeToString(buf.Bytes())
+	com.CommitChainMsg = hex.EncodeToString(p)
 	j, err := json.Marshal(com)
 	if err != nil {
 		return nil, err
@@ -209,6 +126,8 @@ func RevealChain(c *Chain) error {
 	return nil
 }
 
+// GetChainHead returns the KeyMR of the latest Entry Block in the Chain. The
+// error wraps ErrChainNotFound if the Chain does not exist.
 func GetChainHead(chainid string) (*ChainHead, error) {
 	resp, err := http.Get(
 		fmt.Sprintf("http://%s/v1/chain-head/%s", server, chainid))
@@ -222,6 +141,9 @@ func GetChainHead(chainid string) (*ChainHead, error) {
 		if err != nil {
 			return nil, err
 		}
+		if resp.StatusCode == http.StatusBadRequest && bytes.Contains(p, []byte(missingChainHead)) {
+			return nil, fmt.Errorf("%w: %s", ErrChainNotFound, chainid)
+		}
 		return nil, fmt.Errorf(string(p))
 	}
 
@@ -238,51 +160,38 @@ func GetChainHead(chainid string) (*ChainHead, error) {
 	return c, nil
 }
 
-func GetAllChainEntries(chainid string) ([]*Entry, error) {
-	es := make([]*Entry, 0)
-
+// ChainExists reports whether the Chain is in the network. The error is only
+// set if the network could not be asked.
+func ChainExists(chainid string) (bool, error) {
 	head, err := GetChainHead(chainid)
 	if err != nil {
-		return es, err
-	}
-
-	for ebhash := head.ChainHead; ebhash != ZeroHash; {
-		eb, err := GetEBlock(ebhash)
-		if err != nil {
-			return es, err
+		if errors.Is(err, ErrChainNotFound) {
+			return false, nil
 		}
-		s, err := GetAllEBlockEntries(ebhash)
-		if err != nil {
-			return es, err
-		}
-		es = append(s, es...)
-
-		ebhash = eb.Header.PrevKeyMR
+		return false, err
 	}
-
-	return es, nil
+	return head.ChainHead != "" && head.ChainHead != ZeroHash, nil
 }
 
-func GetFirstEntry(chainid string) (*Entry, error) {
-	e := NewEntry()
+func GetAllChainEntries(chainid string) ([]*Entry, error) {
+	es := make([]*Entry, 0)
 
-	head, err := GetChainHead(chainid)
-	if err != nil {
-		return e, err
+	it := NewChainIterator(chainid)
+	for it.Next() {
+		es = append(es, it.Entry())
 	}
 
-	eb, err := GetEBlock(head.ChainHead)
-	if err != nil {
-		return e, err
-	}
+	return es, it.Err()
+}
 
-	for eb.Header.PrevKeyMR != ZeroHash {
-		ebhash := eb.Header.PrevKeyMR
-		eb, err = GetEBlock(ebhash)
-		if err != nil {
-			return e, err
+func GetFirstEntry(chainid string) (*Entry, error) {
+	it := NewChainIterator(chainid)
+	if !it.Next() {
+		if err := it.Err(); err != nil {
+			return NewEntry(), err
 		}
+		return NewEntry(), fmt.Errorf("Chain %s has no Entries", chainid)
 	}
 
-	return GetEntry(eb.EntryList[0].EntryHash)
+	return it.Entry(), nil
 }
@@ -0,0 +1,366 @@
+package factom_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+// testChain serves a Chain from the factomd v1 API. Each Entry Block is in a
+// Directory Block of its own, and is also served in binary with its real KeyMR.
+type testChain struct {
+	*httptest.Server
+	ChainID  string
+	KeyMRs   []string
+	DBKeyMRs []string
+
+	mu      sync.Mutex
+	eblocks map[string]*factom.EBlock
+	raw     map[string][]byte
+	entries map[string]*factom.Entry
+	dblocks map[string]*factom.DBlock
+	heights map[string]int
+	pending []string
+	gets    int
+	ebgets  int
+}
+
+func newTestChain(t *testing.T) *testChain {
+	c := new(testChain)
+	c.ChainID = strings.Repeat("ab", 32)
+	c.eblocks = make(map[string]*factom.EBlock)
+	c.raw = make(map[string][]byte)
+	c.entries = make(map[string]*factom.Entry)
+	c.dblocks = make(map[string]*factom.DBlock)
+	c.heights = make(map[string]int)
+	c.Server = httptest.NewServer(http.HandlerFunc(c.serve))
+	t.Cleanup(c.Close)
+
+	useServer(t, c.URL, false)
+	return c
+}
+
+// add appends an Entry Block with an Entry for each content.
+func (c *testChain) add(contents ...string) []*factom.Entry {
+	es := make([]*factom.Entry, 0)
+	for i, v := range contents {
+		e := factom.NewEntry()
+		e.ChainID = c.ChainID
+		e.ExtIDs = [][]byte{[]byte(fmt.Sprint("block ", len(c.KeyMRs))), []byte(fmt.Sprint("entry ", i))}
+		e.Content = []byte(v)
+		es = append(es, e)
+	}
+	c.addEntries(es...)
+	return es
+}
+
+// addEntries appends an Entry Block with the Entries, one minute apart up to
+// the last minute which holds the rest, in the next Directory Block. Directory
+// Block n is at 10 minutes after the Unix epoch times n.
+func (c *testChain) addEntries(es ...*factom.Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.KeyMRs)
+	eb := new(factom.EBlock)
+	eb.Header.BlockSequenceNumber = n
+	eb.Header.ChainID = c.ChainID
+	eb.Header.PrevKeyMR = factom.ZeroHash
+	if n > 0 {
+		eb.Header.PrevKeyMR = c.KeyMRs[n-1]
+	}
+	db := len(c.DBKeyMRs)
+	eb.Header.Timestamp = uint64(600 * db)
+
+	// the body lists each Entry followed by the marker of its minute
+	body := make([][]byte, 0)
+	for i, e := range es {
+		m := i
+		if m > 9 {
+			m = 9
+		}
+		h := hex.EncodeToString(e.Hash())
+		c.entries[h] = e
+		eb.EntryList = append(eb.EntryList, factom.EBEntry{
+			Timestamp: int64(600*db + 60*m),
+			EntryHash: h,
+		})
+		body = append(body, e.Hash())
+		if m < 9 || i == len(es)-1 {
+			body = append(body, append(make([]byte, 31), byte(m+1)))
+		}
+	}
+
+	header := new(bytes.Buffer)
+	id, _ := hex.DecodeString(c.ChainID)
+	prev, _ := hex.DecodeString(eb.Header.PrevKeyMR)
+	header.Write(id)
+	header.Write(testMerkleRoot(body))
+	header.Write(prev)
+	header.Write(make([]byte, 32))
+	binary.Write(header, binary.BigEndian, []uint32{uint32(n), uint32(db), uint32(len(body))})
+	hh := sha256.Sum256(header.Bytes())
+	k := sha256.Sum256(append(hh[:], testMerkleRoot(body)...))
+	keymr := hex.EncodeToString(k[:])
+
+	c.raw[keymr] = append(header.Bytes(), bytes.Join(body, nil)...)
+	c.eblocks[keymr] = eb
+	c.KeyMRs = append(c.KeyMRs, keymr)
+	c.heights[keymr] = len(c.DBKeyMRs)
+	c.addDBlock(keymr)
+}
+
+// reveal adds an Entry for each content that is pending, not yet in an Entry
+// Block.
+func (c *testChain) reveal(contents ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, v := range contents {
+		e := factom.NewEntry()
+		e.ChainID = c.ChainID
+		e.Content = []byte(v)
+		h := hex.EncodeToString(e.Hash())
+		c.entries[h] = e
+		c.pending = append(c.pending, h)
+	}
+}
+
+// testMerkleRoot returns the Factom merkle root of the hashes.
+func testMerkleRoot(hashes [][]byte) []byte {
+	if len(hashes) == 0 {
+		return make([]byte, 32)
+	}
+	for len(hashes) > 1 {
+		next := make([][]byte, 0)
+		for i := 0; i < len(hashes); i += 2 {
+			j := i + 1
+			if j == len(hashes) {
+				j = i
+			}
+			h := sha256.Sum256(append(append([]byte{}, hashes[i]...), hashes[j]...))
+			next = append(next, h[:])
+		}
+		hashes = next
+	}
+	return hashes[0]
+}
+
+// tick adds a Directory Block without the Chain.
+func (c *testChain) tick() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addDBlock("")
+}
+
+func (c *testChain) addDBlock(keymr string) {
+	n := len(c.DBKeyMRs)
+	d := new(factom.DBlock)
+	d.Header.SequenceNumber = n
+	d.Header.PrevBlockKeyMR = factom.ZeroHash
+	if n > 0 {
+		d.Header.PrevBlockKeyMR = c.DBKeyMRs[n-1]
+	}
+	d.Header.Timestamp = uint64(600 * n)
+
+	type ebref struct {
+		ChainID string
+		KeyMR   string
+	}
+	d.EntryBlockList = append(d.EntryBlockList, ebref{factom.ECChainID, factom.ZeroHash})
+	if keymr != "" {
+		d.EntryBlockList = append(d.EntryBlockList, ebref{c.ChainID, keymr})
+	}
+
+	dbkeymr := fmt.Sprintf("d%063x", n)
+	c.dblocks[dbkeymr] = d
+	c.DBKeyMRs = append(c.DBKeyMRs, dbkeymr)
+}
+
+// Gets returns the number of Entries fetched.
+func (c *testChain) Gets() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gets
+}
+
+// EBlockGets returns the number of Entry Blocks fetched.
+func (c *testChain) EBlockGets() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ebgets
+}
+
+func (c *testChain) serve(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if r.URL.Path == "/v2" {
+		c.serveV2(w, r)
+		return
+	}
+
+	arg := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	var v interface{}
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v1/chain-head/"):
+		if arg != c.ChainID || len(c.KeyMRs) == 0 {
+			http.Error(w, "Missing Chain Head", http.StatusBadRequest)
+			return
+		}
+		v = &factom.ChainHead{ChainHead: c.KeyMRs[len(c.KeyMRs)-1]}
+	case strings.HasPrefix(r.URL.Path, "/v1/entry-block-by-keymr/"):
+		eb, ok := c.eblocks[arg]
+		if !ok {
+			http.Error(w, "Entry Block not found", http.StatusBadRequest)
+			return
+		}
+		c.ebgets++
+		v = eb
+	case strings.HasPrefix(r.URL.Path, "/v1/get-raw-data/"):
+		p, ok := c.raw[arg]
+		if !ok {
+			http.Error(w, "Block not found", http.StatusBadRequest)
+			return
+		}
+		v = &factom.Data{Data: hex.EncodeToString(p)}
+	case strings.HasPrefix(r.URL.Path, "/v1/directory-block-head/"):
+		v = &factom.DBlockHead{KeyMR: c.DBKeyMRs[len(c.DBKeyMRs)-1]}
+	case strings.HasPrefix(r.URL.Path, "/v1/directory-block-by-keymr/"):
+		d, ok := c.dblocks[arg]
+		if !ok {
+			http.Error(w, "Directory Block not found", http.StatusBadRequest)
+			return
+		}
+		v = d
+	case strings.HasPrefix(r.URL.Path, "/v1/entry-by-hash/"):
+		e, ok := c.entries[arg]
+		if !ok {
+			http.Error(w, "Entry not found", http.StatusBadRequest)
+			return
+		}
+		c.gets++
+		v = e
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	p, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(p)
+}
+
+// serveV2 answers the factomd v2 dblock-by-height, entry-block and
+// pending-entries methods.
+func (c *testChain) serveV2(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Method string
+		Params struct {
+			Height int
+			KeyMR  string
+		}
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+
+	var result interface{}
+	switch req.Method {
+	case "dblock-by-height":
+		h := req.Params.Height
+		if h < 0 || h >= len(c.DBKeyMRs) {
+			break
+		}
+		c.gets++
+
+		d := c.dblocks[c.DBKeyMRs[h]]
+		type header struct {
+			PrevKeyMR string `json:"prevkeymr"`
+			Timestamp uint64 `json:"timestamp"`
+			DBHeight  int    `json:"dbheight"`
+		}
+		type dblock struct {
+			KeyMR     string      `json:"keymr"`
+			Header    header      `json:"header"`
+			DBEntries interface{} `json:"dbentries"`
+		}
+		result = map[string]interface{}{"dblock": &dblock{
+			c.DBKeyMRs[h],
+			header{d.Header.PrevBlockKeyMR, d.Header.Timestamp / 60, h},
+			d.EntryBlockList,
+		}}
+	case "pending-entries":
+		ps := make([]map[string]string, 0)
+		for _, h := range c.pending {
+			ps = append(ps, map[string]string{
+				"entryhash": h,
+				"chainid":   c.ChainID,
+				"status":    "TransactionACK",
+			})
+		}
+		ps = append(ps, map[string]string{
+			"entryhash": factom.ZeroHash,
+			"chainid":   factom.ECChainID,
+			"status":    "NotConfirmed",
+		})
+		result = ps
+	case "entry-block":
+		if h, ok := c.heights[req.Params.KeyMR]; ok {
+			result = map[string]interface{}{
+				"header": map[string]interface{}{"dbheight": h},
+			}
+		}
+	}
+
+	if result == nil {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":0,"error":{"code":-32008,"message":"Block not found"}}`))
+		return
+	}
+	p, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      0,
+		"result":  result,
+	})
+	w.Write(p)
+}
+
+func TestChainExists(t *testing.T) {
+	c := newTestChain(t)
+
+	if ok, err := factom.ChainExists(c.ChainID); ok || err != nil {
+		t.Errorf("empty Chain exists: %v %v", ok, err)
+	}
+	if _, err := factom.GetChainHead(c.ChainID); !errors.Is(err, factom.ErrChainNotFound) {
+		t.Errorf("wrong error for a missing Chain: %v", err)
+	}
+
+	c.add("a")
+	if ok, err := factom.ChainExists(c.ChainID); !ok || err != nil {
+		t.Errorf("Chain does not exist: %v %v", ok, err)
+	}
+
+	// other failures are not a missing Chain
+	for _, code := range []int{http.StatusNotFound, http.StatusForbidden, http.StatusBadRequest} {
+		newTestServer(t, map[string]http.HandlerFunc{
+			"/v1/chain-head/": func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "no", code)
+			},
+		})
+		if ok, err := factom.ChainExists(c.ChainID); ok || err == nil {
+			t.Errorf("status %d: Chain exists %v without an error", code, ok)
+		}
+	}
+}
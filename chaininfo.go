@@ -0,0 +1,132 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ChainInfo is a summary of a Chain as of the Entry Block HeadKeyMR.
+type ChainInfo struct {
+	ChainID      string
+	HeadKeyMR    string
+	EBlocks      int
+	Entries      int
+	ContentBytes int64
+
+	// FirstEntryTime and LatestEntryTime are the timestamps of the first and
+	// latest Entries, and are zero for a Chain without Entries.
+	FirstEntryTime  time.Time
+	LatestEntryTime time.Time
+}
+
+// GetChainInfo walks every Entry Block and Entry in the Chain and returns its
+// summary. The error wraps ErrChainNotFound if the Chain does not exist.
+func GetChainInfo(chainid string) (*ChainInfo, error) {
+	return chainInfo(chainid, nil)
+}
+
+// ChainInfoCache keeps the ChainInfo of each Chain it has summarized, so that
+// the next summary of the Chain walks only the Entry Blocks added since.
+type ChainInfoCache struct {
+	mu    sync.Mutex
+	infos map[string]*ChainInfo
+}
+
+func NewChainInfoCache() *ChainInfoCache {
+	c := new(ChainInfoCache)
+	c.infos = make(map[string]*ChainInfo)
+
+	return c
+}
+
+// GetChainInfo returns the summary of the Chain, walking only the Entry Blocks
+// after the cached summary.
+func (c *ChainInfoCache) GetChainInfo(chainid string) (*ChainInfo, error) {
+	c.mu.Lock()
+	prev := c.infos[chainid]
+	c.mu.Unlock()
+
+	info, err := chainInfo(chainid, prev)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.infos[chainid] = info
+	c.mu.Unlock()
+
+	// return a copy so callers cannot change the cache
+	i := *info
+	return &i, nil
+}
+
+// chainInfo walks back from the Chain head to the head of prev, or to the
+// start of the Chain if prev is nil, and adds the Entry Blocks found to prev.
+func chainInfo(chainid string, prev *ChainInfo) (*ChainInfo, error) {
+	head, err := GetChainHead(chainid)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ChainInfo{ChainID: chainid}
+	if prev != nil {
+		*info = *prev
+	}
+	stop := info.HeadKeyMR
+	if stop == "" {
+		stop = ZeroHash
+	}
+
+	ebs := make([]*EBlock, 0)
+	for keymr := head.ChainHead; keymr != stop; {
+		if keymr == ZeroHash {
+			return nil, fmt.Errorf("Cached head %s is not in Chain %s",
+				info.HeadKeyMR, chainid)
+		}
+		eb, err := GetEBlock(keymr)
+		if err != nil {
+			return nil, err
+		}
+		ebs = append(ebs, eb)
+		keymr = eb.Header.PrevKeyMR
+	}
+
+	// oldest first
+	for i := len(ebs) - 1; i >= 0; i-- {
+		info.EBlocks++
+		for _, v := range ebs[i].EntryList {
+			e, err := GetEntry(v.EntryHash)
+			if err != nil {
+				return nil, err
+			}
+			info.Entries++
+			info.ContentBytes += int64(len(e.Content))
+
+			t := time.Unix(v.Timestamp, 0)
+			if info.FirstEntryTime.IsZero() {
+				info.FirstEntryTime = t
+			}
+			info.LatestEntryTime = t
+		}
+	}
+	info.HeadKeyMR = head.ChainHead
+
+	return info, nil
+}
+
+func (c *ChainInfo) String() string {
+	var s string
+	s += fmt.Sprintln("ChainID:", c.ChainID)
+	s += fmt.Sprintln("HeadKeyMR:", c.HeadKeyMR)
+	s += fmt.Sprintln("EBlocks:", c.EBlocks)
+	s += fmt.Sprintln("Entries:", c.Entries)
+	s += fmt.Sprintln("ContentBytes:", c.ContentBytes)
+	s += fmt.Sprintln("FirstEntryTime:", c.FirstEntryTime)
+	s += fmt.Sprintln("LatestEntryTime:", c.LatestEntryTime)
+	return s
+}
@@ -0,0 +1,56 @@
+package factom_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestChainInfo(t *testing.T) {
+	c := newTestChain(t)
+
+	if _, err := factom.GetChainInfo(c.ChainID); !errors.Is(err, factom.ErrChainNotFound) {
+		t.Errorf("wrong error for a missing Chain: %v", err)
+	}
+
+	c.add("one", "two")
+	c.add("three")
+
+	cache := factom.NewChainInfoCache()
+	info, err := cache.GetChainInfo(c.ChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.EBlocks != 2 || info.Entries != 3 || info.ContentBytes != 11 ||
+		info.HeadKeyMR != c.KeyMRs[1] {
+		t.Errorf("wrong ChainInfo\n%s", info)
+	}
+	if !info.FirstEntryTime.Equal(time.Unix(0, 0)) ||
+		!info.LatestEntryTime.Equal(time.Unix(600, 0)) {
+		t.Errorf("wrong Entry times\n%s", info)
+	}
+
+	// only the new Entry Block is walked
+	c.add("four")
+	gets := c.Gets()
+	info, err = cache.GetChainInfo(c.ChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.EBlocks != 3 || info.Entries != 4 || info.ContentBytes != 15 {
+		t.Errorf("wrong ChainInfo\n%s", info)
+	}
+	if n := c.Gets() - gets; n != 1 {
+		t.Errorf("%d Entries fetched for 1 new Entry", n)
+	}
+
+	full, err := factom.GetChainInfo(c.ChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *full != *info {
+		t.Errorf("cached ChainInfo differs\n%s\n%s", full, info)
+	}
+}
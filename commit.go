@@ -0,0 +1,298 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	ed "github.com/FactomProject/ed25519"
+)
+
+// EntryCommit is the payment for an Entry. It is composed and signed by the
+// Entry Credit key before being sent to the network, and is recorded in the
+// Entry Credit Block.
+type EntryCommit struct {
+	Version   uint8
+	MilliTime int64
+	EntryHash []byte
+	Credits   uint8
+	ECPubKey  []byte
+	Sig       []byte
+}
+
+// ChainCommit is the payment for a new Chain and its First Entry.
+type ChainCommit struct {
+	Version     uint8
+	MilliTime   int64
+	ChainIDHash []byte
+	Weld        []byte
+	EntryHash   []byte
+	Credits     uint8
+	ECPubKey    []byte
+	Sig         []byte
+}
+
+// NewEntryCommit returns an unsigned EntryCommit paying for the Entry.
+func NewEntryCommit(e *Entry) (*EntryCommit, error) {
+	c := new(EntryCommit)
+	c.MilliTime = time.Now().UnixNano() / 1e6
+	c.EntryHash = e.Hash()
+
+	d, err := entryCost(e)
+	if err != nil {
+		return nil, err
+	}
+	c.Credits = uint8(d)
+
+	return c, nil
+}
+
+// Sign signs the EntryCommit with the Entry Credit key.
+func (c *EntryCommit) Sign(pub *[32]byte, pri *[64]byte) {
+	sig := ed.Sign(pri, c.MarshalUnsignedBinary())
+	c.ECPubKey = append([]byte{}, pub[:]...)
+	c.Sig = sig[:]
+}
+
+// MarshalUnsignedBinary marshals the part of the EntryCommit that is signed.
+func (c *EntryCommit) MarshalUnsignedBinary() []byte {
+	buf := new(bytes.Buffer)
+
+	// 1 byte version
+	buf.WriteByte(c.Version)
+
+	// 6 byte milliTimestamp (truncated unix time)
+	buf.Write(milliBytes(c.MilliTime))
+
+	// 32 byte Entry Hash
+	buf.Write(c.EntryHash)
+
+	// 1 byte number of entry credits to pay
+	buf.WriteByte(c.Credits)
+
+	return buf.Bytes()
+}
+
+func (c *EntryCommit) MarshalBinary() ([]byte, error) {
+	if len(c.ECPubKey) != 32 || len(c.Sig) != 64 {
+		return nil, fmt.Errorf("EntryCommit is not signed")
+	}
+	buf := bytes.NewBuffer(c.MarshalUnsignedBinary())
+
+	// 32 byte Entry Credit Public Key
+	buf.Write(c.ECPubKey)
+
+	// 64 byte Signature
+	buf.Write(c.Sig)
+
+	return buf.Bytes(), nil
+}
+
+func (c *EntryCommit) UnmarshalBinary(data []byte) error {
+	if len(data) != 136 {
+		return fmt.Errorf("EntryCommit is %d bytes, expected 136", len(data))
+	}
+	buf := bytes.NewBuffer(data)
+
+	c.Version, _ = buf.ReadByte()
+	c.MilliTime = readMilli(buf)
+	c.EntryHash = append([]byte{}, buf.Next(32)...)
+	c.Credits, _ = buf.ReadByte()
+	c.ECPubKey = append([]byte{}, buf.Next(32)...)
+	c.Sig = append([]byte{}, buf.Next(64)...)
+
+	return nil
+}
+
+// TxID returns the hex encoded sha256 hash of the signed part of the
+// EntryCommit, which identifies the commit to the network.
+func (c *EntryCommit) TxID() string {
+	h := sha256.Sum256(c.MarshalUnsignedBinary())
+	return hex.EncodeToString(h[:])
+}
+
+// Verify checks the signature of the EntryCommit.
+func (c *EntryCommit) Verify() bool {
+	return verifySig(c.ECPubKey, c.MarshalUnsignedBinary(), c.Sig)
+}
+
+func (c *EntryCommit) String() string {
+	var s string
+	s += fmt.Sprintln("Version:", c.Version)
+	s += fmt.Sprintln("MilliTime:", c.MilliTime)
+	s += fmt.Sprintln("EntryHash:", hex.EncodeToString(c.EntryHash))
+	s += fmt.Sprintln("Credits:", c.Credits)
+	s += fmt.Sprintln("ECPubKey:", hex.EncodeToString(c.ECPubKey))
+	s += fmt.Sprintln("Sig:", hex.EncodeToString(c.Sig))
+	return s
+}
+
+// NewChainCommit returns an unsigned ChainCommit paying for the Chain.
+func NewChainCommit(ch *Chain) (*ChainCommit, error) {
+	c := new(ChainCommit)
+	c.MilliTime = time.Now().UnixNano() / 1e6
+
+	e := ch.FirstEntry
+
+	cid, err := hex.DecodeString(ch.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	// double sha256 hash of ChainID
+	c.ChainIDHash = shad(cid)
+
+	// Weld; sha256(sha256(EntryHash + ChainID))
+	c.EntryHash = e.Hash()
+	c.Weld = shad(append(e.Hash(), cid...))
+
+	d, err := entryCost(e)
+	if err != nil {
+		return nil, err
+	}
+	c.Credits = uint8(d + 10)
+
+	return c, nil
+}
+
+// Sign signs the ChainCommit with the Entry Credit key.
+func (c *ChainCommit) Sign(pub *[32]byte, pri *[64]byte) {
+	sig := ed.Sign(pri, c.MarshalUnsignedBinary())
+	c.ECPubKey = append([]byte{}, pub[:]...)
+	c.Sig = sig[:]
+}
+
+// MarshalUnsignedBinary marshals the part of the ChainCommit that is signed.
+func (c *ChainCommit) MarshalUnsignedBinary() []byte {
+	buf := new(bytes.Buffer)
+
+	// 1 byte version
+	buf.WriteByte(c.Version)
+
+	// 6 byte milliTimestamp
+	buf.Write(milliBytes(c.MilliTime))
+
+	// 32 byte ChainID Hash
+	buf.Write(c.ChainIDHash)
+
+	// 32 byte Weld
+	buf.Write(c.Weld)
+
+	// 32 byte Entry Hash of the First Entry
+	buf.Write(c.EntryHash)
+
+	// 1 byte number of Entry Credits to pay
+	buf.WriteByte(c.Credits)
+
+	return buf.Bytes()
+}
+
+func (c *ChainCommit) MarshalBinary() ([]byte, error) {
+	if len(c.ECPubKey) != 32 || len(c.Sig) != 64 {
+		return nil, fmt.Errorf("ChainCommit is not signed")
+	}
+	buf := bytes.NewBuffer(c.MarshalUnsignedBinary())
+
+	// 32 byte pubkey
+	buf.Write(c.ECPubKey)
+
+	// 64 byte Signature
+	buf.Write(c.Sig)
+
+	return buf.Bytes(), nil
+}
+
+func (c *ChainCommit) UnmarshalBinary(data []byte) error {
+	if len(data) != 200 {
+		return fmt.Errorf("ChainCommit is %d bytes, expected 200", len(data))
+	}
+	buf := bytes.NewBuffer(data)
+
+	c.Version, _ = buf.ReadByte()
+	c.MilliTime = readMilli(buf)
+	c.ChainIDHash = append([]byte{}, buf.Next(32)...)
+	c.Weld = append([]byte{}, buf.Next(32)...)
+	c.EntryHash = append([]byte{}, buf.Next(32)...)
+	c.Credits, _ = buf.ReadByte()
+	c.ECPubKey = append([]byte{}, buf.Next(32)...)
+	c.Sig = append([]byte{}, buf.Next(64)...)
+
+	return nil
+}
+
+// TxID returns the hex encoded sha256 hash of the signed part of the
+// ChainCommit, which identifies the commit to the network.
+func (c *ChainCommit) TxID() string {
+	h := sha256.Sum256(c.MarshalUnsignedBinary())
+	return hex.EncodeToString(h[:])
+}
+
+// Verify checks the signature of the ChainCommit.
+func (c *ChainCommit) Verify() bool {
+	return verifySig(c.ECPubKey, c.MarshalUnsignedBinary(), c.Sig)
+}
+
+func (c *ChainCommit) String() string {
+	var s string
+	s += fmt.Sprintln("Version:", c.Version)
+	s += fmt.Sprintln("MilliTime:", c.MilliTime)
+	s += fmt.Sprintln("ChainIDHash:", hex.EncodeToString(c.ChainIDHash))
+	s += fmt.Sprintln("Weld:", hex.EncodeToString(c.Weld))
+	s += fmt.Sprintln("EntryHash:", hex.EncodeToString(c.EntryHash))
+	s += fmt.Sprintln("Credits:", c.Credits)
+	s += fmt.Sprintln("ECPubKey:", hex.EncodeToString(c.ECPubKey))
+	s += fmt.Sprintln("Sig:", hex.EncodeToString(c.Sig))
+	return s
+}
+
+// walletCommit sends the unsigned commit message to fctwallet to be signed by
+// the wallet Entry Credit address name and sent to the network.
+func walletCommit(path, name string, msg []byte) error {
+	type walletcommit struct {
+		Message string
+	}
+
+	com := new(walletcommit)
+	com.Message = hex.EncodeToString(msg)
+	j, err := json.Marshal(com)
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(
+		fmt.Sprintf("http://%s/v1/%s/%s", serverFct, path, name),
+		"application/json",
+		bytes.NewBuffer(j))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		p, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf(string(p))
+	}
+
+	return nil
+}
+
+func verifySig(pub, msg, sig []byte) bool {
+	if len(pub) != 32 || len(sig) != 64 {
+		return false
+	}
+	k := new([32]byte)
+	s := new([64]byte)
+	copy(k[:], pub)
+	copy(s[:], sig)
+	return ed.Verify(k, msg, s)
+}
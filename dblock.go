@@ -34,6 +34,7 @@ func GetDBlockHeight() (int, error) {
 
 type DBlock struct {
 	DBHash string
+	KeyMR  string `json:",omitempty"`
 	Header struct {
 		PrevBlockKeyMR string
 		Timestamp      uint64
@@ -68,6 +69,47 @@ func GetDBlock(keymr string) (*DBlock, error) {
 	if err := json.Unmarshal(body, d); err != nil {
 		return nil, fmt.Errorf("%s: %s\n", err, body)
 	}
+	d.KeyMR = keymr
+
+	return d, nil
+}
+
+// GetDBlockByHeight returns the Directory Block at the height from the factomd
+// v2 api.
+func GetDBlockByHeight(height int) (*DBlock, error) {
+	type params struct {
+		Height int `json:"height"`
+	}
+	type result struct {
+		DBlock struct {
+			DBHash string `json:"dbhash"`
+			KeyMR  string `json:"keymr"`
+			Header struct {
+				PrevKeyMR string `json:"prevkeymr"`
+				Timestamp uint64 `json:"timestamp"`
+				DBHeight  int    `json:"dbheight"`
+			} `json:"header"`
+			// decoded without tags to match the EntryBlockList type
+			DBEntries []struct {
+				ChainID string
+				KeyMR   string
+			} `json:"dbentries"`
+		} `json:"dblock"`
+	}
+
+	r := new(result)
+	if err := factomdRequest("dblock-by-height", &params{height}, r); err != nil {
+		return nil, err
+	}
+
+	d := new(DBlock)
+	d.DBHash = r.DBlock.DBHash
+	d.KeyMR = r.DBlock.KeyMR
+	d.Header.PrevBlockKeyMR = r.DBlock.Header.PrevKeyMR
+	// the v2 header timestamp is in minutes
+	d.Header.Timestamp = r.DBlock.Header.Timestamp * 60
+	d.Header.SequenceNumber = r.DBlock.Header.DBHeight
+	d.EntryBlockList = r.DBlock.DBEntries
 
 	return d, nil
 }
@@ -95,6 +137,7 @@ func GetDBlockHead() (*DBlockHead, error) {
 
 func (d *DBlock) String() string {
 	var s string
+	s += fmt.Sprintln("KeyMR:", d.KeyMR)
 	s += fmt.Sprintln("PrevBlockKeyMR:", d.Header.PrevBlockKeyMR)
 	s += fmt.Sprintln("Timestamp:", d.Header.Timestamp)
 	s += fmt.Sprintln("SequenceNumber:", d.Header.SequenceNumber)
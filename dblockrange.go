@@ -0,0 +1,242 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// DBlockRange walks the Directory Blocks from one height to another, in
+// either direction, fetching up to Prefetch blocks ahead in parallel while
+// returning them in order.
+//
+//	r := factom.NewDBlockRange(1000, 2000)
+//	defer r.Close()
+//	for r.Next() {
+//		d := r.DBlock()
+//		...
+//	}
+//	if err := r.Err(); err != nil {
+//		...
+//	}
+type DBlockRange struct {
+	// Prefetch is the number of blocks fetched ahead. It must be set before
+	// the first call to Next.
+	Prefetch int
+
+	// Index, if set, is used to fetch the blocks by KeyMR and is filled in
+	// with the blocks fetched by height.
+	Index *DBlockIndex
+
+	start, end int
+
+	started bool
+	pending chan chan *dblockResult
+	done    chan struct{}
+	once    sync.Once
+
+	block *DBlock
+	err   error
+}
+
+type dblockResult struct {
+	block *DBlock
+	err   error
+}
+
+// NewDBlockRange returns a walker over the Directory Blocks from height start
+// to end inclusive. If end is below start the blocks are walked backwards.
+func NewDBlockRange(start, end int) *DBlockRange {
+	r := new(DBlockRange)
+	r.Prefetch = 8
+	r.start = start
+	r.end = end
+	r.done = make(chan struct{})
+
+	return r
+}
+
+// Next advances to the next Directory Block and reports whether there is one.
+func (r *DBlockRange) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	if !r.started {
+		r.started = true
+		r.fetch()
+	}
+
+	ch, ok := <-r.pending
+	if !ok {
+		r.block = nil
+		return false
+	}
+	res := <-ch
+	if res.err != nil {
+		r.err = res.err
+		r.block = nil
+		r.Close()
+		return false
+	}
+	r.block = res.block
+	return true
+}
+
+// DBlock returns the current Directory Block.
+func (r *DBlockRange) DBlock() *DBlock {
+	return r.block
+}
+
+// Err returns the error that stopped the walk, if any.
+func (r *DBlockRange) Err() error {
+	return r.err
+}
+
+// Close stops fetching ahead. It must be called if the walk is stopped before
+// Next returns false.
+func (r *DBlockRange) Close() error {
+	r.once.Do(func() { close(r.done) })
+	return nil
+}
+
+// fetch starts fetching the blocks of the range in order, with up to Prefetch
+// fetches running at once.
+func (r *DBlockRange) fetch() {
+	n := r.Prefetch
+	if n < 1 {
+		n = 1
+	}
+	r.pending = make(chan chan *dblockResult, n)
+	sem := make(chan struct{}, n)
+
+	step := 1
+	if r.end < r.start {
+		step = -1
+	}
+
+	go func() {
+		defer close(r.pending)
+		for h := r.start; h != r.end+step; h += step {
+			select {
+			case sem <- struct{}{}:
+			case <-r.done:
+				return
+			}
+
+			ch := make(chan *dblockResult, 1)
+			go func(h int) {
+				d, err := r.Index.GetDBlock(h)
+				ch <- &dblockResult{d, err}
+				<-sem
+			}(h)
+
+			select {
+			case r.pending <- ch:
+			case <-r.done:
+				return
+			}
+		}
+	}()
+}
+
+// DBlockIndex maps Directory Block heights to KeyMRs. It may be saved and
+// loaded so that later jobs can find blocks by KeyMR without the v2 api.
+type DBlockIndex struct {
+	mu     sync.RWMutex
+	keymrs map[int]string
+}
+
+func NewDBlockIndex() *DBlockIndex {
+	ix := new(DBlockIndex)
+	ix.keymrs = make(map[int]string)
+
+	return ix
+}
+
+// LoadDBlockIndex reads an index saved by Save.
+func LoadDBlockIndex(path string) (*DBlockIndex, error) {
+	p, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ix := NewDBlockIndex()
+	if err := json.Unmarshal(p, &ix.keymrs); err != nil {
+		return nil, err
+	}
+	return ix, nil
+}
+
+// Save writes the index to path.
+func (ix *DBlockIndex) Save(path string) error {
+	ix.mu.RLock()
+	p, err := json.Marshal(ix.keymrs)
+	ix.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, p, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Add records the KeyMR of the block at the height.
+func (ix *DBlockIndex) Add(height int, keymr string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.keymrs[height] = keymr
+}
+
+// KeyMR returns the KeyMR of the block at the height, looking it up and adding
+// it to the index if it is not already there.
+func (ix *DBlockIndex) KeyMR(height int) (string, error) {
+	ix.mu.RLock()
+	keymr, ok := ix.keymrs[height]
+	ix.mu.RUnlock()
+	if ok {
+		return keymr, nil
+	}
+
+	d, err := ix.GetDBlock(height)
+	if err != nil {
+		return "", err
+	}
+	return d.KeyMR, nil
+}
+
+// GetDBlock returns the Directory Block at the height, by KeyMR if the height
+// is in the index and by height otherwise. A nil index always fetches by
+// height.
+func (ix *DBlockIndex) GetDBlock(height int) (*DBlock, error) {
+	if ix == nil {
+		return GetDBlockByHeight(height)
+	}
+
+	ix.mu.RLock()
+	keymr, ok := ix.keymrs[height]
+	ix.mu.RUnlock()
+	if ok {
+		return GetDBlock(keymr)
+	}
+
+	d, err := GetDBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	ix.Add(height, d.KeyMR)
+	return d, nil
+}
+
+// Len returns the number of heights in the index.
+func (ix *DBlockIndex) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.keymrs)
+}
@@ -0,0 +1,96 @@
+package factom_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestGetDBlockByHeight(t *testing.T) {
+	c := newTestChain(t)
+	c.add("a")
+	c.tick()
+
+	d, err := factom.GetDBlockByHeight(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1, err := factom.GetDBlock(c.DBKeyMRs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(d, v1) {
+		t.Errorf("Directory Blocks by height and KeyMR differ\n%s\n%s", d, v1)
+	}
+
+	if _, err := factom.GetDBlockByHeight(2); err == nil {
+		t.Error("missing Directory Block was found")
+	}
+}
+
+func heights(t *testing.T, r *factom.DBlockRange) []int {
+	hs := make([]int, 0)
+	for r.Next() {
+		hs = append(hs, r.DBlock().Header.SequenceNumber)
+	}
+	return hs
+}
+
+func TestDBlockRange(t *testing.T) {
+	c := newTestChain(t)
+	for i := 0; i < 20; i++ {
+		c.tick()
+	}
+
+	r := factom.NewDBlockRange(3, 17)
+	r.Prefetch = 4
+	r.Index = factom.NewDBlockIndex()
+	hs := heights(t, r)
+	if r.Err() != nil || len(hs) != 15 || hs[0] != 3 || hs[14] != 17 {
+		t.Errorf("walked %v: %v", hs, r.Err())
+	}
+	for i := 1; i < len(hs); i++ {
+		if hs[i] != hs[i-1]+1 {
+			t.Errorf("walked out of order %v", hs)
+			break
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "index")
+	if err := r.Index.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	ix, err := factom.LoadDBlockIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ix.Len() != 15 {
+		t.Errorf("%d heights indexed", ix.Len())
+	}
+
+	// the indexed blocks are fetched by KeyMR
+	gets := c.Gets()
+	r = factom.NewDBlockRange(10, 5)
+	r.Index = ix
+	hs = heights(t, r)
+	if r.Err() != nil || !reflect.DeepEqual(hs, []int{10, 9, 8, 7, 6, 5}) {
+		t.Errorf("walked %v: %v", hs, r.Err())
+	}
+	if n := c.Gets() - gets; n != 0 {
+		t.Errorf("%d blocks fetched by height", n)
+	}
+	if keymr, err := ix.KeyMR(19); err != nil || keymr != c.DBKeyMRs[19] {
+		t.Errorf("wrong KeyMR %s: %v", keymr, err)
+	}
+
+	// stopping early and walking past the end
+	r = factom.NewDBlockRange(0, 100)
+	r.Next()
+	r.Close()
+	r = factom.NewDBlockRange(18, 25)
+	if hs := heights(t, r); len(hs) != 2 || r.Err() == nil {
+		t.Errorf("walked %v past the end: %v", hs, r.Err())
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EBlockRef is an Entry Block in an EBlockIndex.
+type EBlockRef struct {
+	KeyMR     string
+	Sequence  int
+	Timestamp time.Time
+}
+
+// EBlockIndex lists the Entry Blocks of Chains in order so that they can be
+// found by sequence number or time without walking the Chain. The index of a
+// Chain is built by walking its Entry Blocks, but not its Entries, once, and
+// Update then walks only the Entry Blocks added since. It may be saved and
+// loaded between runs.
+type EBlockIndex struct {
+	// Source is where the Entry Blocks are read from, the Network unless
+	// set to a Mirror. Queries from the index also read from it.
+	Source Source
+
+	// DBlocks, if set, is used to find Directory Blocks by height.
+	DBlocks *DBlockIndex
+
+	mu     sync.RWMutex
+	chains map[string][]EBlockRef
+}
+
+func NewEBlockIndex() *EBlockIndex {
+	ix := new(EBlockIndex)
+	ix.Source = Network
+	ix.chains = make(map[string][]EBlockRef)
+
+	return ix
+}
+
+// LoadEBlockIndex reads an index saved by Save.
+func LoadEBlockIndex(path string) (*EBlockIndex, error) {
+	p, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ix := NewEBlockIndex()
+	if err := json.Unmarshal(p, &ix.chains); err != nil {
+		return nil, err
+	}
+	return ix, nil
+}
+
+// Save writes the index to path.
+func (ix *EBlockIndex) Save(path string) error {
+	ix.mu.RLock()
+	p, err := json.Marshal(ix.chains)
+	ix.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, p, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// EBlocks returns the indexed Entry Blocks of the Chain, oldest first, without
+// updating them.
+func (ix *EBlockIndex) EBlocks(chainid string) []EBlockRef {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return append([]EBlockRef{}, ix.chains[chainid]...)
+}
+
+// Update adds the Entry Blocks of the Chain after those already indexed and
+// returns every indexed Entry Block, oldest first.
+func (ix *EBlockIndex) Update(chainid string) ([]EBlockRef, error) {
+	refs := ix.EBlocks(chainid)
+	stop := ZeroHash
+	if n := len(refs); n > 0 {
+		stop = refs[n-1].KeyMR
+	}
+
+	head, err := ix.Source.GetChainHead(chainid)
+	if err != nil {
+		return nil, err
+	}
+
+	added := make([]EBlockRef, 0)
+	for keymr := head.ChainHead; keymr != stop; {
+		if keymr == ZeroHash {
+			return nil, fmt.Errorf("Indexed Entry Block %s is not in Chain %s", stop, chainid)
+		}
+		eb, err := ix.Source.GetEBlock(keymr)
+		if err != nil {
+			return nil, err
+		}
+		added = append(added, EBlockRef{keymr, eb.Header.BlockSequenceNumber, eb.Time()})
+		keymr = eb.Header.PrevKeyMR
+	}
+	for i := len(added) - 1; i >= 0; i-- {
+		if added[i].Sequence != len(refs) {
+			return nil, fmt.Errorf("Entry Block %s is number %d not %d",
+				added[i].KeyMR, added[i].Sequence, len(refs))
+		}
+		refs = append(refs, added[i])
+	}
+
+	ix.mu.Lock()
+	ix.chains[chainid] = refs
+	ix.mu.Unlock()
+
+	return append([]EBlockRef{}, refs...), nil
+}
+
+// Query updates the index of the Chain and returns a Query of the Entries with
+// timestamps from from up to but not including to, oldest first. The Entry
+// Blocks that may hold them are found by binary search on the index, so only
+// those Entry Blocks, and only the Entries in the range, are fetched.
+func (ix *EBlockIndex) Query(chainid string, from, to time.Time, ps ...EntryPredicate) (*Query, error) {
+	refs, err := ix.Update(chainid)
+	if err != nil {
+		return nil, err
+	}
+
+	// an Entry Block holds the Entries of the 10 minutes from its timestamp,
+	// so the block before the first one starting at or after from may also
+	// hold Entries in the range
+	i := sort.Search(len(refs), func(i int) bool {
+		return !refs[i].Timestamp.Before(from)
+	})
+	if i > 0 {
+		i--
+	}
+	j := len(refs)
+	if !to.IsZero() {
+		j = sort.Search(len(refs), func(j int) bool {
+			return !refs[j].Timestamp.Before(to)
+		})
+	}
+
+	it := NewChainIterator(chainid).UseSource(ix.Source)
+	it.started = true
+	it.blocks = make([]*chainBlock, 0)
+	for k := j - 1; k >= i; k-- {
+		it.blocks = append(it.blocks, &chainBlock{keymr: refs[k].KeyMR})
+	}
+
+	return newQuery(it, ps).Between(from, to), nil
+}
+
+// QueryTimeRange returns a Query of the Entries in the Chain with timestamps
+// from from up to but not including to, oldest first, using a new EBlockIndex.
+// Keep an EBlockIndex between queries of the same Chain to avoid walking its
+// Entry Blocks each time.
+func QueryTimeRange(chainid string, from, to time.Time, ps ...EntryPredicate) (*Query, error) {
+	return NewEBlockIndex().Query(chainid, from, to, ps...)
+}
@@ -0,0 +1,79 @@
+package factom_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestEBlockIndexQuery(t *testing.T) {
+	c := newTestChain(t)
+	for i := 0; i < 20; i++ {
+		c.add(fmt.Sprint(i, "a "), fmt.Sprint(i, "b "))
+		if i%3 == 0 {
+			c.tick()
+		}
+	}
+
+	ix := factom.NewEBlockIndex()
+	refs, err := ix.Update(c.ChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 20 || refs[19].KeyMR != c.KeyMRs[19] || refs[19].Sequence != 19 {
+		t.Fatalf("wrong index %v", refs)
+	}
+
+	// Entry Block 10 is in Directory Block 14 and 11 in 15
+	from, to := time.Unix(14*600+60, 0), time.Unix(15*600+60, 0)
+	gets, ebgets := c.Gets(), c.EBlockGets()
+	q, err := ix.Query(c.ChainID, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := query(t, q); s != "10b 11a " {
+		t.Errorf("found %q", s)
+	}
+	if n := c.Gets() - gets; n != 2 {
+		t.Errorf("%d Entries fetched for 2", n)
+	}
+	if n := c.EBlockGets() - ebgets; n > 3 {
+		t.Errorf("%d Entry Blocks fetched", n)
+	}
+
+	// the saved index is updated with only the new Entry Blocks
+	path := filepath.Join(t.TempDir(), "index")
+	if err := ix.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	ix, err = factom.LoadEBlockIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.add("20a ")
+	ebgets = c.EBlockGets()
+	if refs, err := ix.Update(c.ChainID); err != nil || len(refs) != 21 {
+		t.Fatalf("%d Entry Blocks indexed: %v", len(refs), err)
+	}
+	if n := c.EBlockGets() - ebgets; n != 1 {
+		t.Errorf("%d Entry Blocks fetched for 1 new", n)
+	}
+
+	q, err = factom.QueryTimeRange(c.ChainID, time.Unix(26*600, 0), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := query(t, q); s != "19a 19b 20a " {
+		t.Errorf("found %q", s)
+	}
+	q, err = ix.Query(c.ChainID, time.Time{}, time.Unix(60, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := query(t, q); s != "0a " {
+		t.Errorf("found %q", s)
+	}
+}
@@ -5,10 +5,15 @@
 package factom
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 )
 
 func GetAllEBlockEntries(ebhash string) ([]*Entry, error) {
@@ -30,6 +35,8 @@ func GetAllEBlockEntries(ebhash string) ([]*Entry, error) {
 	return es, nil
 }
 
+// EBlock is an Entry Block. The Header Timestamp is the start of the
+// Directory Block holding it, in seconds since the Unix epoch.
 type EBlock struct {
 	Header struct {
 		BlockSequenceNumber int
@@ -40,11 +47,23 @@ type EBlock struct {
 	EntryList []EBEntry
 }
 
+// EBEntry is an Entry listed in an Entry Block. The Timestamp is the minute the
+// Entry was recorded, in seconds since the Unix epoch.
 type EBEntry struct {
 	Timestamp int64
 	EntryHash string
 }
 
+// Time returns the start of the Directory Block holding the Entry Block.
+func (e *EBlock) Time() time.Time {
+	return time.Unix(int64(e.Header.Timestamp), 0)
+}
+
+// Time returns the minute the Entry was recorded.
+func (e EBEntry) Time() time.Time {
+	return time.Unix(e.Timestamp, 0)
+}
+
 func GetEBlock(keymr string) (*EBlock, error) {
 	resp, err := http.Get(
 		fmt.Sprintf("http://%s/v1/entry-block-by-keymr/%s", server, keymr))
@@ -68,6 +87,80 @@ func GetEBlock(keymr string) (*EBlock, error) {
 	return e, nil
 }
 
+// GetEBlockDBHeight returns the height of the Directory Block holding the
+// Entry Block from the factomd v2 api.
+func GetEBlockDBHeight(keymr string) (int, error) {
+	type params struct {
+		KeyMR string `json:"keymr"`
+	}
+	type result struct {
+		Header struct {
+			DBHeight int `json:"dbheight"`
+		} `json:"header"`
+	}
+
+	r := new(result)
+	if err := factomdRequest("entry-block", &params{keymr}, r); err != nil {
+		return 0, err
+	}
+	return r.Header.DBHeight, nil
+}
+
+// rawEBlock is an Entry Block decoded from its binary form. Unlike the v1 api
+// JSON it carries every field of the header, so its KeyMR can be recomputed.
+type rawEBlock struct {
+	KeyMR       string
+	ChainID     string
+	PrevKeyMR   string
+	Sequence    int
+	DBHeight    int
+	EntryHashes []string
+}
+
+// decodeRawEBlock decodes the binary Entry Block and computes its KeyMR,
+// sha256(sha256(header) + BodyMR), after checking the BodyMR against the body.
+func decodeRawEBlock(raw []byte) (*rawEBlock, error) {
+	// ChainID, BodyMR, PrevKeyMR, PrevFullHash, then 4 byte sequence,
+	// Directory Block height and body length
+	const headerLen = 4*32 + 3*4
+	if len(raw) < headerLen {
+		return nil, fmt.Errorf("Entry Block header is too short")
+	}
+	header, body := raw[:headerLen], raw[headerLen:]
+
+	n := binary.BigEndian.Uint32(header[4*32+8:])
+	if uint64(len(body)) != uint64(n)*32 {
+		return nil, fmt.Errorf("Entry Block body is %d bytes not %d hashes", len(body), n)
+	}
+
+	b := new(rawEBlock)
+	b.ChainID = hex.EncodeToString(header[:32])
+	b.PrevKeyMR = hex.EncodeToString(header[64:96])
+	b.Sequence = int(binary.BigEndian.Uint32(header[4*32:]))
+	b.DBHeight = int(binary.BigEndian.Uint32(header[4*32+4:]))
+
+	hashes := make([][]byte, 0, n)
+	for i := 0; i < len(body); i += 32 {
+		h := body[i : i+32]
+		hashes = append(hashes, h)
+		// minute markers are 31 zero bytes and the minute, 1 to 10
+		if bytes.Count(h[:31], []byte{0}) == 31 && h[31] >= 1 && h[31] <= 10 {
+			continue
+		}
+		b.EntryHashes = append(b.EntryHashes, hex.EncodeToString(h))
+	}
+
+	bodyMR := merkleRoot(hashes)
+	if !bytes.Equal(bodyMR, header[32:64]) {
+		return nil, fmt.Errorf("Entry Block BodyMR does not match its body")
+	}
+	h := sha256.Sum256(header)
+	keymr := sha256.Sum256(append(h[:], bodyMR...))
+	b.KeyMR = hex.EncodeToString(keymr[:])
+
+	return b, nil
+}
+
 func (e *EBlock) String() string {
 	var s string
 	s += fmt.Sprintln("BlockSequenceNumber:", e.Header.BlockSequenceNumber)
@@ -0,0 +1,267 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+const ECChainID = "000000000000000000000000000000000000000000000000000000000000000c"
+
+// Entry Credit Block entry types
+const (
+	ECIDServerIndexNumber = iota
+	ECIDMinuteNumber
+	ECIDChainCommit
+	ECIDEntryCommit
+	ECIDBalanceIncrease
+)
+
+// ECBlock is an Entry Credit Block recording the commits paid for and the
+// Entry Credits purchased during a Directory Block.
+type ECBlock struct {
+	KeyMR          string
+	BodyHash       string
+	PrevHeaderHash string
+	PrevFullHash   string
+	DBHeight       uint32
+	Entries        []ECBlockEntry
+}
+
+// ECBlockEntry is one of *ServerIndexNumber, *MinuteNumber, *ChainCommit,
+// *EntryCommit, or *BalanceIncrease.
+type ECBlockEntry interface {
+	ECID() byte
+}
+
+// ServerIndexNumber marks the server that built the following part of the
+// block.
+type ServerIndexNumber struct {
+	Number uint8
+}
+
+// MinuteNumber marks the end of a minute of the block.
+type MinuteNumber struct {
+	Number uint8
+}
+
+// BalanceIncrease credits an Entry Credit public key with the Entry Credits
+// bought by the output Index of the Factoid Transaction TxID.
+type BalanceIncrease struct {
+	ECPubKey []byte
+	TxID     string
+	Index    uint64
+	NumEC    uint64
+}
+
+func (s *ServerIndexNumber) ECID() byte { return ECIDServerIndexNumber }
+func (m *MinuteNumber) ECID() byte      { return ECIDMinuteNumber }
+func (c *ChainCommit) ECID() byte       { return ECIDChainCommit }
+func (c *EntryCommit) ECID() byte       { return ECIDEntryCommit }
+func (b *BalanceIncrease) ECID() byte   { return ECIDBalanceIncrease }
+
+// GetECBlock fetches and decodes the Entry Credit Block with the KeyMR.
+func GetECBlock(keymr string) (*ECBlock, error) {
+	raw, err := GetRaw(keymr)
+	if err != nil {
+		return nil, err
+	}
+
+	e := new(ECBlock)
+	if err := e.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	e.KeyMR = keymr
+
+	return e, nil
+}
+
+func (e *ECBlock) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	// 32 byte ChainID
+	p, _ := hex.DecodeString(ECChainID)
+	buf.Write(p)
+
+	// 32 byte BodyHash, PrevHeaderHash, and PrevFullHash
+	for _, h := range []string{e.BodyHash, e.PrevHeaderHash, e.PrevFullHash} {
+		if err := writeHash(buf, h); err != nil {
+			return nil, err
+		}
+	}
+
+	// 4 byte Directory Block Height
+	binary.Write(buf, binary.BigEndian, e.DBHeight)
+
+	// varint Header Expansion Size
+	writeVarInt(buf, 0)
+
+	body := new(bytes.Buffer)
+	for _, v := range e.Entries {
+		body.WriteByte(v.ECID())
+		switch v := v.(type) {
+		case *ServerIndexNumber:
+			body.WriteByte(v.Number)
+		case *MinuteNumber:
+			body.WriteByte(v.Number)
+		case *BalanceIncrease:
+			body.Write(v.ECPubKey)
+			if err := writeHash(body, v.TxID); err != nil {
+				return nil, err
+			}
+			writeVarInt(body, v.Index)
+			writeVarInt(body, v.NumEC)
+		case interface {
+			MarshalBinary() ([]byte, error)
+		}:
+			p, err := v.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			body.Write(p)
+		default:
+			return nil, fmt.Errorf("Unknown ECBlock entry type %d", v.ECID())
+		}
+	}
+
+	// 8 byte Object Count
+	binary.Write(buf, binary.BigEndian, uint64(len(e.Entries)))
+
+	// 8 byte Body Size
+	binary.Write(buf, binary.BigEndian, uint64(body.Len()))
+
+	buf.Write(body.Bytes())
+
+	return buf.Bytes(), nil
+}
+
+func (e *ECBlock) UnmarshalBinary(data []byte) error {
+	buf := bytes.NewBuffer(data)
+
+	if buf.Len() < 32*4+4 {
+		return fmt.Errorf("Entry Credit Block header is too short")
+	}
+
+	// 32 byte ChainID
+	if c := hex.EncodeToString(buf.Next(32)); c != ECChainID {
+		return fmt.Errorf("Invalid Entry Credit Block ChainID %s", c)
+	}
+
+	e.BodyHash = hex.EncodeToString(buf.Next(32))
+	e.PrevHeaderHash = hex.EncodeToString(buf.Next(32))
+	e.PrevFullHash = hex.EncodeToString(buf.Next(32))
+	e.DBHeight = binary.BigEndian.Uint32(buf.Next(4))
+
+	if err := skipExpansion(buf); err != nil {
+		return err
+	}
+
+	if buf.Len() < 16 {
+		return fmt.Errorf("Entry Credit Block header is too short")
+	}
+	count := binary.BigEndian.Uint64(buf.Next(8))
+	size := binary.BigEndian.Uint64(buf.Next(8))
+	if uint64(buf.Len()) != size {
+		return fmt.Errorf("Entry Credit Block body is %d bytes, expected %d",
+			buf.Len(), size)
+	}
+
+	e.Entries = nil
+	for i := uint64(0); i < count; i++ {
+		id, err := buf.ReadByte()
+		if err != nil {
+			return fmt.Errorf("Entry Credit Block body ended after %d entries", i)
+		}
+
+		var v ECBlockEntry
+		switch id {
+		case ECIDServerIndexNumber:
+			s := new(ServerIndexNumber)
+			if s.Number, err = buf.ReadByte(); err != nil {
+				return err
+			}
+			v = s
+		case ECIDMinuteNumber:
+			m := new(MinuteNumber)
+			if m.Number, err = buf.ReadByte(); err != nil {
+				return err
+			}
+			v = m
+		case ECIDChainCommit:
+			c := new(ChainCommit)
+			if buf.Len() < 200 {
+				return fmt.Errorf("ChainCommit %d is too short", i)
+			}
+			if err := c.UnmarshalBinary(buf.Next(200)); err != nil {
+				return err
+			}
+			v = c
+		case ECIDEntryCommit:
+			c := new(EntryCommit)
+			if buf.Len() < 136 {
+				return fmt.Errorf("EntryCommit %d is too short", i)
+			}
+			if err := c.UnmarshalBinary(buf.Next(136)); err != nil {
+				return err
+			}
+			v = c
+		case ECIDBalanceIncrease:
+			b := new(BalanceIncrease)
+			if buf.Len() < 64 {
+				return fmt.Errorf("BalanceIncrease %d is too short", i)
+			}
+			b.ECPubKey = append([]byte{}, buf.Next(32)...)
+			b.TxID = hex.EncodeToString(buf.Next(32))
+			if b.Index, err = readVarInt(buf); err != nil {
+				return err
+			}
+			if b.NumEC, err = readVarInt(buf); err != nil {
+				return err
+			}
+			v = b
+		default:
+			return fmt.Errorf("Unknown ECBlock entry type %d", id)
+		}
+		e.Entries = append(e.Entries, v)
+	}
+
+	return nil
+}
+
+func (e *ECBlock) String() string {
+	var s string
+	s += fmt.Sprintln("KeyMR:", e.KeyMR)
+	s += fmt.Sprintln("BodyHash:", e.BodyHash)
+	s += fmt.Sprintln("PrevHeaderHash:", e.PrevHeaderHash)
+	s += fmt.Sprintln("PrevFullHash:", e.PrevFullHash)
+	s += fmt.Sprintln("DBHeight:", e.DBHeight)
+	for _, v := range e.Entries {
+		switch v := v.(type) {
+		case *ServerIndexNumber:
+			s += fmt.Sprintln("ServerIndexNumber:", v.Number)
+		case *MinuteNumber:
+			s += fmt.Sprintln("MinuteNumber:", v.Number)
+		case *ChainCommit:
+			s += fmt.Sprintln("ChainCommit {")
+			s += v.String()
+			s += fmt.Sprintln("}")
+		case *EntryCommit:
+			s += fmt.Sprintln("EntryCommit {")
+			s += v.String()
+			s += fmt.Sprintln("}")
+		case *BalanceIncrease:
+			s += fmt.Sprintln("BalanceIncrease {")
+			s += fmt.Sprintln("	ECPubKey", ECAddressString(v.ECPubKey))
+			s += fmt.Sprintln("	TxID", v.TxID)
+			s += fmt.Sprintln("	Index", v.Index)
+			s += fmt.Sprintln("	NumEC", v.NumEC)
+			s += fmt.Sprintln("}")
+		}
+	}
+	return s
+}
@@ -0,0 +1,63 @@
+package factom_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	ed "github.com/FactomProject/ed25519"
+	"github.com/FactomProject/factom"
+)
+
+func TestECBlockBinary(t *testing.T) {
+	pub, pri, err := ed.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := factom.NewEntry()
+	if err := e.UnmarshalJSON(jsonentry); err != nil {
+		t.Error(err)
+	}
+	ec, err := factom.NewEntryCommit(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec.Sign(pub, pri)
+	cc, err := factom.NewChainCommit(factom.NewChain(e))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc.Sign(pub, pri)
+
+	b := new(factom.ECBlock)
+	b.BodyHash = factom.ZeroHash
+	b.PrevHeaderHash = factom.ZeroHash
+	b.PrevFullHash = factom.ZeroHash
+	b.DBHeight = 12
+	b.Entries = []factom.ECBlockEntry{
+		&factom.ServerIndexNumber{0},
+		ec,
+		cc,
+		&factom.BalanceIncrease{pub[:], factom.ZeroHash, 1, 100},
+		&factom.MinuteNumber{1},
+	}
+
+	p, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2 := new(factom.ECBlock)
+	if err := b2.UnmarshalBinary(p); err != nil {
+		t.Fatal(err)
+	}
+	if len(b2.Entries) != len(b.Entries) {
+		t.Fatalf("Entry Credit Block did not round trip\n%s", b2)
+	}
+	if c, ok := b2.Entries[1].(*factom.EntryCommit); !ok || !c.Verify() {
+		t.Error("EntryCommit did not verify")
+	}
+	if c, ok := b2.Entries[2].(*factom.ChainCommit); !ok || !c.Verify() {
+		t.Error("ChainCommit did not verify")
+	}
+	t.Log(b2)
+}
@@ -9,13 +9,20 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-
-	ed "github.com/FactomProject/ed25519"
 )
 
+// ErrEntryNotFound is returned, wrapped with the Entry Hash, when the network
+// has no Entry with the hash.
+var ErrEntryNotFound = errors.New("Entry not found")
+
+// missingEntry is the error factomd answers with, as a bad request, for an
+// Entry it does not have.
+const missingEntry = "Entry not found"
+
 type Entry struct {
 	ChainID string
 	ExtIDs  [][]byte
@@ -32,52 +39,12 @@ func NewEntry() *Entry {
 // the factom network. Once the payment is verified and the network is commited
 // to publishing the Entry it may be published with a call to RevealEntry.
 func CommitEntry(e *Entry, name string) error {
-	type walletcommit struct {
-		Message string
-	}
-
-	buf := new(bytes.Buffer)
-
-	// 1 byte version
-	buf.Write([]byte{0})
-
-	// 6 byte milliTimestamp (truncated unix time)
-	buf.Write(milliTime())
-
-	// 32 byte Entry Hash
-	buf.Write(e.Hash())
-
-	// 1 byte number of entry credits to pay
-	if c, err := entryCost(e); err != nil {
-		return err
-	} else {
-		buf.WriteByte(byte(c))
-	}
-
-	com := new(walletcommit)
-	com.Message = hex.EncodeToString(buf.Bytes())
-	j, err := json.Marshal(com)
-	if err != nil {
-		return err
-	}
-	resp, err := http.Post(
-		fmt.Sprintf("http://%s/v1/commit-entry/%s", serverFct, name),
-		"application/json",
-		bytes.NewBuffer(j))
+	c, err := NewEntryCommit(e)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		p, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf(string(p))
-	}
-
-	return nil
+	return walletCommit("commit-entry", name, c.MarshalUnsignedBinary())
 }
 
 func ComposeEntryCommit(pub *[32]byte, pri *[64]byte, e *Entry) ([]byte, error) {
@@ -85,35 +52,21 @@ func ComposeEntryCommit(pub *[32]byte, pri *[64]byte, e *Entry) ([]byte, error)
 		CommitEntryMsg string
 	}
 
-	buf := new(bytes.Buffer)
-
-	// 1 byte version
-	buf.Write([]byte{0})
-
-	// 6 byte milliTimestamp (truncated unix time)
-	buf.Write(milliTime())
-
-	// 32 byte Entry Hash
-	buf.Write(e.Hash())
-
-	// 1 byte number of entry credits to pay
-	if c, err := entryCost(e); err != nil {
+	c, err := NewEntryCommit(e)
+	if err != nil {
 		return nil, err
-	} else {
-		buf.WriteByte(byte(c))
 	}
 
 	// sign the commit
-	sig := ed.Sign(pri, buf.Bytes())
-
-	// 32 byte Entry Credit Public Key
-	buf.Write(pub[:])
+	c.Sign(pub, pri)
 
-	// 64 byte Signature
-	buf.Write(sig[:])
+	p, err := c.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
 
 	com := new(commit)
-	com.CommitEntryMsg = hex.EncodeToString(buf.Bytes())
+	com.CommitEntryMsg = hex.EncodeToString(p)
 	j, err := json.Marshal(com)
 	if err != nil {
 		return nil, err
@@ -191,6 +144,9 @@ func GetEntry(hash string) (*Entry, error) {
 		return nil, err
 	}
 	if resp.StatusCode != 200 {
+		if resp.StatusCode == http.StatusBadRequest && bytes.Contains(body, []byte(missingEntry)) {
+			return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, hash)
+		}
 		return nil, fmt.Errorf(string(body))
 	}
 
@@ -202,6 +158,18 @@ func GetEntry(hash string) (*Entry, error) {
 	return e, nil
 }
 
+// entryExists reports whether the Entry is in the network. The error is only
+// set if the network could not be asked.
+func entryExists(hash string) (bool, error) {
+	if _, err := GetEntry(hash); err != nil {
+		if errors.Is(err, ErrEntryNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 func (e *Entry) Hash() []byte {
 	a, err := e.MarshalBinary()
 	if err != nil {
@@ -245,6 +213,45 @@ func (e *Entry) MarshalBinary() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+func (e *Entry) UnmarshalBinary(data []byte) error {
+	buf := bytes.NewBuffer(data)
+
+	if buf.Len() < 35 {
+		return fmt.Errorf("Entry header is too short")
+	}
+
+	// 1 byte Version
+	if v, _ := buf.ReadByte(); v != 0 {
+		return fmt.Errorf("Unknown Entry version %d", v)
+	}
+
+	// 32 byte chainid
+	e.ChainID = hex.EncodeToString(buf.Next(32))
+
+	// 2 byte size of extids
+	n := int(binary.BigEndian.Uint16(buf.Next(2)))
+	if buf.Len() < n {
+		return fmt.Errorf("Entry ExtIDs are too short")
+	}
+	ids := bytes.NewBuffer(buf.Next(n))
+
+	e.ExtIDs = nil
+	for ids.Len() > 0 {
+		if ids.Len() < 2 {
+			return fmt.Errorf("Invalid ExtID length")
+		}
+		l := int(binary.BigEndian.Uint16(ids.Next(2)))
+		if ids.Len() < l {
+			return fmt.Errorf("ExtID is too short")
+		}
+		e.ExtIDs = append(e.ExtIDs, append([]byte{}, ids.Next(l)...))
+	}
+
+	e.Content = append([]byte{}, buf.Bytes()...)
+
+	return nil
+}
+
 func (e *Entry) MarshalExtIDsBinary() ([]byte, error) {
 	buf := new(bytes.Buffer)
 
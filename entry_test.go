@@ -45,6 +45,25 @@ func TestUnmarshalJSON(t *testing.T) {
 	t.Log(e2)
 }
 
+func TestEntryBinary(t *testing.T) {
+	e1 := factom.NewEntry()
+	if err := e1.UnmarshalJSON(jsonentry); err != nil {
+		t.Error(err)
+	}
+	p, err := e1.MarshalBinary()
+	if err != nil {
+		t.Error(err)
+	}
+
+	e2 := factom.NewEntry()
+	if err := e2.UnmarshalBinary(p); err != nil {
+		t.Error(err)
+	}
+	if string(e1.Hash()) != string(e2.Hash()) {
+		t.Errorf("Entry did not round trip\n%s", e2)
+	}
+}
+
 func TestComposeEntryCommit(t *testing.T) {
 	pub, pri, err := ed.GenerateKey(rand.Reader)
 	if err != nil {
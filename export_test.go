@@ -0,0 +1,6 @@
+package factom
+
+// Wallet returns the global fctwallet server string so tests can restore it.
+func Wallet() string {
+	return serverFct
+}
@@ -0,0 +1,148 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FactoshisPerFactoid is the number of factoshis in one factoid
+const FactoshisPerFactoid = 100000000
+
+var ErrFactoshiOverflow = errors.New("Factoshi amount overflow")
+
+// Factoshi is an amount of factoids counted in factoshis, the smallest unit of
+// 1e-8 factoid.
+type Factoshi int64
+
+// ParseFactoshi parses an exact decimal number of factoids such as "12.5" or
+// "-0.00000001". More than 8 decimal places is an error rather than being
+// rounded.
+func ParseFactoshi(s string) (Factoshi, error) {
+	str := strings.TrimSpace(s)
+
+	neg := false
+	switch {
+	case strings.HasPrefix(str, "-"):
+		neg = true
+		str = str[1:]
+	case strings.HasPrefix(str, "+"):
+		str = str[1:]
+	}
+
+	whole, frac := str, ""
+	if i := strings.Index(str, "."); i >= 0 {
+		whole, frac = str[:i], str[i+1:]
+	}
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("Invalid factoid amount %q", s)
+	}
+	if len(frac) > 8 {
+		return 0, fmt.Errorf("Factoid amount %q has more than 8 decimal places", s)
+	}
+	for _, c := range whole + frac {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("Invalid factoid amount %q", s)
+		}
+	}
+	frac += strings.Repeat("0", 8-len(frac))
+
+	var w, f int64
+	var err error
+	if whole != "" {
+		if w, err = strconv.ParseInt(whole, 10, 64); err != nil {
+			return 0, ErrFactoshiOverflow
+		}
+	}
+	if f, err = strconv.ParseInt(frac, 10, 64); err != nil {
+		return 0, fmt.Errorf("Invalid factoid amount %q", s)
+	}
+
+	a, err := Factoshi(w).Mul(FactoshisPerFactoid)
+	if err != nil {
+		return 0, err
+	}
+	if a, err = a.Add(Factoshi(f)); err != nil {
+		return 0, err
+	}
+	if neg {
+		a = -a
+	}
+	return a, nil
+}
+
+// String formats the amount in factoids without trailing zeros, e.g. "12.5".
+func (f Factoshi) String() string {
+	sign := ""
+	u := uint64(f)
+	if f < 0 {
+		sign = "-"
+		u = uint64(-f)
+	}
+	s := fmt.Sprintf("%s%d", sign, u/FactoshisPerFactoid)
+	if r := u % FactoshisPerFactoid; r != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%08d", r), "0")
+	}
+	return s
+}
+
+// Factoids returns the amount as a floating point number of factoids for
+// display. It is not exact and should not be used for arithmetic.
+func (f Factoshi) Factoids() float64 {
+	return float64(f) / FactoshisPerFactoid
+}
+
+// Add returns f + g or ErrFactoshiOverflow.
+func (f Factoshi) Add(g Factoshi) (Factoshi, error) {
+	s := f + g
+	if (g > 0 && s < f) || (g < 0 && s > f) {
+		return 0, ErrFactoshiOverflow
+	}
+	return s, nil
+}
+
+// Sub returns f - g or ErrFactoshiOverflow.
+func (f Factoshi) Sub(g Factoshi) (Factoshi, error) {
+	s := f - g
+	if (g > 0 && s > f) || (g < 0 && s < f) {
+		return 0, ErrFactoshiOverflow
+	}
+	return s, nil
+}
+
+// Mul returns f * n or ErrFactoshiOverflow.
+func (f Factoshi) Mul(n int64) (Factoshi, error) {
+	if f == 0 || n == 0 {
+		return 0, nil
+	}
+	p := f * Factoshi(n)
+	if p/Factoshi(n) != f || (f == -1 && n == math.MinInt64) ||
+		(n == -1 && f == math.MinInt64) {
+		return 0, ErrFactoshiOverflow
+	}
+	return p, nil
+}
+
+// ToEC returns the number of whole Entry Credits the amount buys at the
+// exchange rate of factoshis per Entry Credit.
+func (f Factoshi) ToEC(rate Factoshi) (int64, error) {
+	if rate <= 0 {
+		return 0, fmt.Errorf("Invalid exchange rate %d", rate)
+	}
+	return int64(f / rate), nil
+}
+
+// FactoshiFromEC returns the cost in factoshis of ec Entry Credits at the
+// exchange rate of factoshis per Entry Credit.
+func FactoshiFromEC(ec int64, rate Factoshi) (Factoshi, error) {
+	if rate <= 0 {
+		return 0, fmt.Errorf("Invalid exchange rate %d", rate)
+	}
+	return rate.Mul(ec)
+}
@@ -0,0 +1,71 @@
+package factom_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestParseFactoshi(t *testing.T) {
+	good := map[string]factom.Factoshi{
+		"12.5":       1250000000,
+		"0.00000001": 1,
+		"-1.25":      -125000000,
+		".5":         50000000,
+		"3":          300000000,
+		"3.":         300000000,
+	}
+	for s, want := range good {
+		f, err := factom.ParseFactoshi(s)
+		if err != nil {
+			t.Errorf("%s: %s", s, err)
+		}
+		if f != want {
+			t.Errorf("%s parsed as %d, want %d", s, f, want)
+		}
+	}
+
+	bad := []string{"", ".", "1.000000001", "1e8", "abc", "99999999999999999999"}
+	for _, s := range bad {
+		if f, err := factom.ParseFactoshi(s); err == nil {
+			t.Errorf("%q parsed as %d", s, f)
+		}
+	}
+}
+
+func TestFactoshiString(t *testing.T) {
+	strs := map[factom.Factoshi]string{
+		1250000000:    "12.5",
+		1:             "0.00000001",
+		-125000000:    "-1.25",
+		0:             "0",
+		math.MinInt64: "-92233720368.54775808",
+	}
+	for f, want := range strs {
+		if s := f.String(); s != want {
+			t.Errorf("%d formatted as %s, want %s", f, s, want)
+		}
+	}
+}
+
+func TestFactoshiOverflow(t *testing.T) {
+	max := factom.Factoshi(math.MaxInt64)
+	if _, err := max.Add(1); err != factom.ErrFactoshiOverflow {
+		t.Error("Add did not overflow")
+	}
+	if _, err := factom.Factoshi(math.MinInt64).Sub(1); err != factom.ErrFactoshiOverflow {
+		t.Error("Sub did not overflow")
+	}
+	if _, err := max.Mul(2); err != factom.ErrFactoshiOverflow {
+		t.Error("Mul did not overflow")
+	}
+
+	ec, err := factom.Factoshi(1e8).ToEC(1000)
+	if err != nil {
+		t.Error(err)
+	}
+	if ec != 100000 {
+		t.Errorf("wrong EC %d", ec)
+	}
+}
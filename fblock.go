@@ -0,0 +1,182 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+const FactoidChainID = "000000000000000000000000000000000000000000000000000000000000000f"
+
+// FBlock is a Factoid Block holding the Factoid Transactions for a Directory
+// Block.
+type FBlock struct {
+	KeyMR           string
+	BodyMR          string
+	PrevKeyMR       string
+	PrevLedgerKeyMR string
+	ExchRate        Factoshi
+	DBHeight        uint32
+	Transactions    []*Transaction
+
+	// EndOfMinute is the number of Transactions in the block at the end of
+	// each minute.
+	EndOfMinute [10]int
+}
+
+// GetFBlock fetches and decodes the Factoid Block with the KeyMR.
+func GetFBlock(keymr string) (*FBlock, error) {
+	raw, err := GetRaw(keymr)
+	if err != nil {
+		return nil, err
+	}
+
+	f := new(FBlock)
+	if err := f.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	f.KeyMR = keymr
+
+	return f, nil
+}
+
+// GetFBlockHead fetches the most recent Factoid Block.
+func GetFBlockHead() (*FBlock, error) {
+	head, err := GetChainHead(FactoidChainID)
+	if err != nil {
+		return nil, err
+	}
+	return GetFBlock(head.ChainHead)
+}
+
+func (f *FBlock) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	// 32 byte ChainID
+	p, _ := hex.DecodeString(FactoidChainID)
+	buf.Write(p)
+
+	// 32 byte BodyMR, PrevKeyMR, and PrevLedgerKeyMR
+	for _, h := range []string{f.BodyMR, f.PrevKeyMR, f.PrevLedgerKeyMR} {
+		if err := writeHash(buf, h); err != nil {
+			return nil, err
+		}
+	}
+
+	// 8 byte Exchange Rate
+	binary.Write(buf, binary.BigEndian, uint64(f.ExchRate))
+
+	// 4 byte Directory Block Height
+	binary.Write(buf, binary.BigEndian, f.DBHeight)
+
+	// varint Header Expansion Size
+	writeVarInt(buf, 0)
+
+	body := new(bytes.Buffer)
+	minute := 0
+	for i, t := range f.Transactions {
+		for ; minute < 10 && f.EndOfMinute[minute] <= i; minute++ {
+			body.WriteByte(0)
+		}
+		p, err := t.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		body.Write(p)
+	}
+	for ; minute < 10; minute++ {
+		body.WriteByte(0)
+	}
+
+	// 4 byte Transaction Count
+	binary.Write(buf, binary.BigEndian, uint32(len(f.Transactions)))
+
+	// 4 byte Body Size
+	binary.Write(buf, binary.BigEndian, uint32(body.Len()))
+
+	buf.Write(body.Bytes())
+
+	return buf.Bytes(), nil
+}
+
+func (f *FBlock) UnmarshalBinary(data []byte) error {
+	buf := bytes.NewBuffer(data)
+
+	if buf.Len() < 32*4+8+4 {
+		return fmt.Errorf("Factoid Block header is too short")
+	}
+
+	// 32 byte ChainID
+	if c := hex.EncodeToString(buf.Next(32)); c != FactoidChainID {
+		return fmt.Errorf("Invalid Factoid Block ChainID %s", c)
+	}
+
+	f.BodyMR = hex.EncodeToString(buf.Next(32))
+	f.PrevKeyMR = hex.EncodeToString(buf.Next(32))
+	f.PrevLedgerKeyMR = hex.EncodeToString(buf.Next(32))
+	f.ExchRate = Factoshi(binary.BigEndian.Uint64(buf.Next(8)))
+	f.DBHeight = binary.BigEndian.Uint32(buf.Next(4))
+
+	if err := skipExpansion(buf); err != nil {
+		return err
+	}
+
+	if buf.Len() < 8 {
+		return fmt.Errorf("Factoid Block header is too short")
+	}
+	count := binary.BigEndian.Uint32(buf.Next(4))
+	size := binary.BigEndian.Uint32(buf.Next(4))
+	if uint32(buf.Len()) != size {
+		return fmt.Errorf("Factoid Block body is %d bytes, expected %d",
+			buf.Len(), size)
+	}
+
+	f.Transactions = make([]*Transaction, 0, count)
+	minute := 0
+	for buf.Len() > 0 {
+		// a 0 byte marks the end of a minute. Transactions start with the
+		// non-zero version.
+		if buf.Bytes()[0] == 0 {
+			buf.ReadByte()
+			if minute < 10 {
+				f.EndOfMinute[minute] = len(f.Transactions)
+			}
+			minute++
+			continue
+		}
+
+		t := new(Transaction)
+		if err := t.unmarshal(buf); err != nil {
+			return fmt.Errorf("Transaction %d: %s", len(f.Transactions), err)
+		}
+		f.Transactions = append(f.Transactions, t)
+	}
+
+	if uint32(len(f.Transactions)) != count {
+		return fmt.Errorf("Factoid Block has %d transactions, expected %d",
+			len(f.Transactions), count)
+	}
+
+	return nil
+}
+
+func (f *FBlock) String() string {
+	var s string
+	s += fmt.Sprintln("KeyMR:", f.KeyMR)
+	s += fmt.Sprintln("BodyMR:", f.BodyMR)
+	s += fmt.Sprintln("PrevKeyMR:", f.PrevKeyMR)
+	s += fmt.Sprintln("PrevLedgerKeyMR:", f.PrevLedgerKeyMR)
+	s += fmt.Sprintln("ExchRate:", f.ExchRate)
+	s += fmt.Sprintln("DBHeight:", f.DBHeight)
+	for _, t := range f.Transactions {
+		s += fmt.Sprintln("Transaction {")
+		s += t.String()
+		s += fmt.Sprintln("}")
+	}
+	return s
+}
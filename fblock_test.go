@@ -0,0 +1,47 @@
+package factom_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	ed "github.com/FactomProject/ed25519"
+	"github.com/FactomProject/factom"
+)
+
+func TestFBlockBinary(t *testing.T) {
+	pub, pri, err := ed.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := factom.NewTransaction()
+	tx.AddInput(pub, 1e8)
+	tx.AddOutput(factom.FctAddressString(make([]byte, 32)), 1e8-12000)
+	tx.Sign(pri)
+
+	f := new(factom.FBlock)
+	f.BodyMR = factom.ZeroHash
+	f.PrevKeyMR = factom.ZeroHash
+	f.PrevLedgerKeyMR = factom.ZeroHash
+	f.ExchRate = 1000
+	f.DBHeight = 12
+	f.Transactions = []*factom.Transaction{factom.NewTransaction(), tx}
+	f.EndOfMinute = [10]int{1, 1, 1, 2, 2, 2, 2, 2, 2, 2}
+
+	p, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f2 := new(factom.FBlock)
+	if err := f2.UnmarshalBinary(p); err != nil {
+		t.Fatal(err)
+	}
+	if len(f2.Transactions) != 2 || f2.EndOfMinute != f.EndOfMinute {
+		t.Errorf("Factoid Block did not round trip\n%s", f2)
+	}
+	if err := f2.Transactions[1].Verify(); err != nil {
+		t.Error(err)
+	}
+	t.Log(f2)
+}
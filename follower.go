@@ -0,0 +1,260 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// FollowerCheckpoint is how far a Follower has delivered. EBlockKeyMR is the
+// last Entry Block delivered from the Directory Block at DBHeight, or empty if
+// every Entry Block at DBHeight has been delivered.
+type FollowerCheckpoint struct {
+	DBHeight    int
+	EBlockKeyMR string `json:",omitempty"`
+}
+
+// LoadFollowerCheckpoint reads a checkpoint saved by SaveFollowerCheckpoint.
+func LoadFollowerCheckpoint(path string) (*FollowerCheckpoint, error) {
+	p, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cp := new(FollowerCheckpoint)
+	if err := json.Unmarshal(p, cp); err != nil {
+		return nil, fmt.Errorf("Checkpoint %s: %s", path, err)
+	}
+	return cp, nil
+}
+
+// SaveFollowerCheckpoint writes the checkpoint to path, replacing it in one
+// step so a crash cannot leave a partial checkpoint.
+func SaveFollowerCheckpoint(path string, cp *FollowerCheckpoint) error {
+	p, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, p, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Follower watches the Directory Blocks as they are produced and delivers the
+// Entries of the followed Chains in the order they were recorded.
+type Follower struct {
+	// PollInterval is how often the Directory Block head is checked.
+	PollInterval time.Duration
+
+	// CheckpointFile, if set, is where the checkpoint is loaded from when
+	// the Follower starts and saved to after each Entry Block is delivered.
+	CheckpointFile string
+
+	// OnError, if set, is called with the errors from the network. The
+	// Follower retries them at the next poll.
+	OnError func(error)
+
+	chains map[string]bool
+
+	mu sync.Mutex
+	cp *FollowerCheckpoint
+}
+
+func NewFollower(chainids ...string) *Follower {
+	f := new(Follower)
+	f.PollInterval = 10 * time.Second
+	f.chains = make(map[string]bool)
+	for _, id := range chainids {
+		f.chains[id] = true
+	}
+
+	return f
+}
+
+// SetCheckpoint sets where the Follower resumes. Without a checkpoint the
+// Follower starts after the current Directory Block head.
+func (f *Follower) SetCheckpoint(cp *FollowerCheckpoint) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c := *cp
+	f.cp = &c
+}
+
+// Checkpoint returns how far the Follower has delivered.
+func (f *Follower) Checkpoint() *FollowerCheckpoint {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cp == nil {
+		return nil
+	}
+	c := *f.cp
+	return &c
+}
+
+// Run delivers the Entries of the followed Chains to fn until the context is
+// done, fn returns an error, or the checkpoint cannot be saved. An Entry Block is checkpointed only after fn
+// has returned for every Entry in it, so after a restart the Entries of a
+// partly delivered Entry Block are delivered again.
+func (f *Follower) Run(ctx context.Context, fn func(*EntryRecord) error) error {
+	if f.Checkpoint() == nil && f.CheckpointFile != "" {
+		cp, err := LoadFollowerCheckpoint(f.CheckpointFile)
+		if err == nil {
+			f.SetCheckpoint(cp)
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	t := time.NewTicker(f.PollInterval)
+	defer t.Stop()
+	for {
+		if err := f.poll(ctx, fn); err != nil {
+			var rerr runError
+			if errors.As(err, &rerr) {
+				return rerr.err
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if f.OnError != nil {
+				f.OnError(err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// runError is an error that ends Run rather than being retried at the next
+// poll: one returned by the function passed to Run, or a failed checkpoint
+// save.
+type runError struct {
+	err error
+}
+
+func (e runError) Error() string {
+	return e.err.Error()
+}
+
+// poll delivers the Entries from every Directory Block after the checkpoint,
+// walking forward from it and delivering each block as it is fetched, so the
+// checkpoint advances even if a later fetch fails.
+func (f *Follower) poll(ctx context.Context, fn func(*EntryRecord) error) error {
+	head, err := GetDBlockHead()
+	if err != nil {
+		return err
+	}
+	if head.KeyMR == ZeroHash || head.KeyMR == "" {
+		return nil
+	}
+	d, err := GetDBlock(head.KeyMR)
+	if err != nil {
+		return err
+	}
+	top := d.Header.SequenceNumber
+
+	cp := f.Checkpoint()
+	if cp == nil {
+		// start after the current head
+		return f.setCheckpoint(&FollowerCheckpoint{DBHeight: top})
+	}
+
+	// the checkpoint is included if it is partly done
+	start := cp.DBHeight + 1
+	if cp.EBlockKeyMR != "" {
+		start = cp.DBHeight
+	}
+	if start > top {
+		return nil
+	}
+
+	r := NewDBlockRange(start, top)
+	defer r.Close()
+	for r.Next() {
+		d := r.DBlock()
+		h := d.Header.SequenceNumber
+
+		// skip the Entry Blocks already delivered from a partly done block
+		skip := ""
+		if h == cp.DBHeight {
+			skip = cp.EBlockKeyMR
+		}
+
+		for _, v := range d.EntryBlockList {
+			if skip != "" {
+				if v.KeyMR == skip {
+					skip = ""
+				}
+				continue
+			}
+			if !f.chains[v.ChainID] {
+				continue
+			}
+			if err := f.deliver(ctx, h, v.KeyMR, fn); err != nil {
+				return err
+			}
+			if err := f.setCheckpoint(&FollowerCheckpoint{h, v.KeyMR}); err != nil {
+				return err
+			}
+		}
+		if err := f.setCheckpoint(&FollowerCheckpoint{DBHeight: h}); err != nil {
+			return err
+		}
+	}
+	return r.Err()
+}
+
+// deliver passes each Entry in the Entry Block to fn.
+func (f *Follower) deliver(ctx context.Context, height int, keymr string, fn func(*EntryRecord) error) error {
+	eb, err := GetEBlock(keymr)
+	if err != nil {
+		return err
+	}
+	for i, v := range eb.EntryList {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		e, err := GetEntry(v.EntryHash)
+		if err != nil {
+			return err
+		}
+		if err := fn(newEntryRecord(e, keymr, eb, i, height)); err != nil {
+			return runError{err}
+		}
+	}
+	return nil
+}
+
+// setCheckpoint records how far the Follower has delivered. A checkpoint that
+// cannot be saved ends Run, since a restart would deliver everything since the
+// last saved checkpoint again.
+func (f *Follower) setCheckpoint(cp *FollowerCheckpoint) error {
+	f.SetCheckpoint(cp)
+	if f.CheckpointFile != "" {
+		if err := SaveFollowerCheckpoint(f.CheckpointFile, cp); err != nil {
+			return runError{err}
+		}
+	}
+	return nil
+}
+
+func (cp *FollowerCheckpoint) String() string {
+	var s string
+	s += fmt.Sprintln("DBHeight:", cp.DBHeight)
+	s += fmt.Sprintln("EBlockKeyMR:", cp.EBlockKeyMR)
+	return s
+}
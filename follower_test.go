@@ -0,0 +1,156 @@
+package factom_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+func follow(t *testing.T, f *factom.Follower, n int) (string, error) {
+	var s string
+	stop := errors.New("stop")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := f.Run(ctx, func(e *factom.EntryRecord) error {
+		if e.EntryHash != hex.EncodeToString(e.Entry.Hash()) || int64(e.DBHeight) != e.Timestamp.Unix()/600 {
+			t.Errorf("wrong record\n%s", e)
+		}
+		s += string(e.Entry.Content)
+		if len(s) == n {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		return s, err
+	}
+	return s, nil
+}
+
+func TestFollower(t *testing.T) {
+	c := newTestChain(t)
+	c.add("a")
+	c.tick()
+	c.add("b", "c")
+	c.tick()
+	c.add("d")
+
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	f := factom.NewFollower(c.ChainID)
+	f.PollInterval = time.Millisecond
+	f.CheckpointFile = path
+	f.SetCheckpoint(&factom.FollowerCheckpoint{DBHeight: 1})
+
+	s, err := follow(t, f, 3)
+	if err != nil || s != "bcd" {
+		t.Fatalf("followed %q: %v", s, err)
+	}
+	// the Entry Block of d was not finished
+	if cp := f.Checkpoint(); cp.DBHeight != 3 || cp.EBlockKeyMR != "" {
+		t.Errorf("wrong checkpoint\n%s", cp)
+	}
+
+	// resume from the saved checkpoint and follow new blocks
+	f = factom.NewFollower(c.ChainID)
+	f.PollInterval = time.Millisecond
+	f.CheckpointFile = path
+	c.add("e")
+	s, err = follow(t, f, 2)
+	if err != nil || s != "de" {
+		t.Fatalf("followed %q after resuming: %v", s, err)
+	}
+
+	cp, err := factom.LoadFollowerCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.DBHeight != 4 || cp.EBlockKeyMR != "" {
+		t.Errorf("wrong saved checkpoint\n%s", cp)
+	}
+
+	// the delivered Entry Blocks of a partly done Directory Block are skipped
+	f = factom.NewFollower(c.ChainID)
+	f.PollInterval = time.Millisecond
+	f.SetCheckpoint(&factom.FollowerCheckpoint{DBHeight: 2, EBlockKeyMR: c.KeyMRs[1]})
+	s, err = follow(t, f, 2)
+	if err != nil || s != "de" {
+		t.Errorf("followed %q from a partly done block: %v", s, err)
+	}
+}
+
+func TestFollowerFailedFetch(t *testing.T) {
+	c := newTestChain(t)
+	c.add("a")
+	c.add("b")
+	c.add("c")
+
+	// the Directory Block at height 2 cannot be fetched by height
+	var mu sync.Mutex
+	fail := true
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(p))
+		mu.Lock()
+		defer mu.Unlock()
+		if fail && bytes.Contains(p, []byte(`"dblock-by-height","params":{"height":2}`)) {
+			http.Error(w, "unavailable", http.StatusInternalServerError)
+			return
+		}
+		c.serve(w, r)
+	}))
+	defer proxy.Close()
+	useServer(t, proxy.URL, false)
+
+	f := factom.NewFollower(c.ChainID)
+	f.PollInterval = time.Millisecond
+	f.SetCheckpoint(&factom.FollowerCheckpoint{DBHeight: 0})
+	failures := 0
+	f.OnError = func(error) { failures++ }
+
+	// the blocks before the failed fetch are delivered and checkpointed
+	var s string
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	f.Run(ctx, func(e *factom.EntryRecord) error {
+		s += string(e.Entry.Content)
+		return nil
+	})
+	if s != "b" || failures == 0 {
+		t.Errorf("followed %q with %d failures", s, failures)
+	}
+	if cp := f.Checkpoint(); cp.DBHeight != 1 || cp.EBlockKeyMR != "" {
+		t.Errorf("wrong checkpoint\n%s", cp)
+	}
+
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	if s, err := follow(t, f, 1); err != nil || s != "c" {
+		t.Errorf("followed %q after the fetch was fixed: %v", s, err)
+	}
+}
+
+func TestFollowerCheckpointSaveError(t *testing.T) {
+	c := newTestChain(t)
+	c.add("a")
+
+	f := factom.NewFollower(c.ChainID)
+	f.PollInterval = time.Millisecond
+	f.CheckpointFile = filepath.Join(t.TempDir(), "missing", "checkpoint")
+	var perr *os.PathError
+	if _, err := follow(t, f, 1); !errors.As(err, &perr) {
+		t.Errorf("Run did not return the checkpoint save error: %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// Direction is the direction of the funds of an AddressTransaction relative
+// to the address.
+type Direction int
+
+const (
+	Received Direction = iota
+	Sent
+)
+
+func (d Direction) String() string {
+	if d == Sent {
+		return "sent"
+	}
+	return "received"
+}
+
+// AddressTransaction is a Factoid Transaction touching an address.
+type AddressTransaction struct {
+	TxID      string
+	Address   string
+	Direction Direction
+
+	// Amount is the net amount received by or sent from the address. For an
+	// EC address it is the factoshis converted to Entry Credits.
+	Amount Factoshi
+	Fee    Factoshi
+
+	DBHeight    uint32
+	FBlockKeyMR string
+	Timestamp   time.Time
+	Transaction *Transaction
+}
+
+// HistoryCheckpoint is the last Factoid Block included in an address history.
+// Passing it to AddressHistorySince scans only the blocks after it.
+type HistoryCheckpoint struct {
+	DBHeight uint32
+	KeyMR    string
+}
+
+// AddressHistory returns the Transactions touching the human readable Factoid
+// or Entry Credit address in the Factoid Blocks from Directory Block height
+// start to end inclusive, oldest first.
+func AddressHistory(addr string, start, end uint32) ([]*AddressTransaction, error) {
+	if end < start {
+		return nil, fmt.Errorf("Invalid height range %d to %d", start, end)
+	}
+	ts, _, err := addressHistory(addr, nil, func(f *FBlock) bool {
+		return f.DBHeight < start
+	}, func(f *FBlock) bool {
+		return f.DBHeight <= end
+	})
+	return ts, err
+}
+
+// AddressHistorySince returns the Transactions touching the human readable
+// Factoid or Entry Credit address in every Factoid Block after the checkpoint,
+// oldest first, and the checkpoint for the next call, which is cp again if
+// there are no new blocks. A nil checkpoint scans from the genesis block.
+func AddressHistorySince(addr string, cp *HistoryCheckpoint) ([]*AddressTransaction, *HistoryCheckpoint, error) {
+	return addressHistory(addr, cp, func(f *FBlock) bool {
+		return cp != nil && (f.KeyMR == cp.KeyMR || f.DBHeight <= cp.DBHeight)
+	}, func(f *FBlock) bool {
+		return true
+	})
+}
+
+// addressHistory walks the Factoid Blocks back from the head until stop, and
+// collects the Transactions touching addr from the blocks matching include.
+// The checkpoint returned is the head, or since if no block is walked.
+func addressHistory(addr string, since *HistoryCheckpoint, stop, include func(*FBlock) bool) ([]*AddressTransaction, *HistoryCheckpoint, error) {
+	match, err := addressMatcher(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	head, err := GetChainHead(FactoidChainID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cp := since
+	walked := false
+	ts := make([]*AddressTransaction, 0)
+	for keymr := head.ChainHead; keymr != ZeroHash; {
+		f, err := GetFBlock(keymr)
+		if err != nil {
+			return nil, nil, err
+		}
+		if stop(f) {
+			break
+		}
+		if !walked {
+			walked = true
+			cp = &HistoryCheckpoint{f.DBHeight, f.KeyMR}
+		}
+
+		if include(f) {
+			// the block is walked backwards so its transactions are too
+			for i := len(f.Transactions) - 1; i >= 0; i-- {
+				if a := match(f.Transactions[i]); a != nil {
+					a.Address = addr
+					a.DBHeight = f.DBHeight
+					a.FBlockKeyMR = f.KeyMR
+					ts = append(ts, a)
+				}
+			}
+		}
+
+		keymr = f.PrevKeyMR
+	}
+
+	// oldest first
+	for i, j := 0, len(ts)-1; i < j; i, j = i+1, j-1 {
+		ts[i], ts[j] = ts[j], ts[i]
+	}
+	return ts, cp, nil
+}
+
+// addressMatcher returns a function that converts a Transaction touching the
+// human readable address into an AddressTransaction, and returns nil for any
+// other Transaction.
+func addressMatcher(addr string) (func(*Transaction) *AddressTransaction, error) {
+	var ins, outs func(*Transaction) []*TransAddress
+
+	key, err := ParseFctAddress(addr)
+	if err == nil {
+		ins = func(t *Transaction) []*TransAddress { return t.Inputs }
+		outs = func(t *Transaction) []*TransAddress { return t.Outputs }
+	} else if key, err = ParseECAddress(addr); err == nil {
+		ins = func(t *Transaction) []*TransAddress { return nil }
+		outs = func(t *Transaction) []*TransAddress { return t.ECOutputs }
+	} else {
+		return nil, fmt.Errorf("%s is not a Factoid or Entry Credit address", addr)
+	}
+
+	return func(t *Transaction) *AddressTransaction {
+		found := false
+		var net Factoshi
+		for _, v := range ins(t) {
+			if bytes.Equal(v.Address, key) {
+				net -= v.Amount
+				found = true
+			}
+		}
+		for _, v := range outs(t) {
+			if bytes.Equal(v.Address, key) {
+				net += v.Amount
+				found = true
+			}
+		}
+		if !found {
+			return nil
+		}
+
+		a := new(AddressTransaction)
+		a.TxID, _ = t.TxID()
+		a.Direction = Received
+		a.Amount = net
+		if net < 0 {
+			a.Direction = Sent
+			a.Amount = -net
+		}
+		// a coinbase Transaction has no inputs and pays no fee
+		if f, err := t.Fee(); err == nil && f > 0 {
+			a.Fee = f
+		}
+		a.Timestamp = time.Unix(0, t.MilliTimestamp*1e6)
+		a.Transaction = t
+		return a
+	}, nil
+}
+
+func (a *AddressTransaction) String() string {
+	var s string
+	s += fmt.Sprintln("TxID:", a.TxID)
+	s += fmt.Sprintln("Address:", a.Address)
+	s += fmt.Sprintln("Direction:", a.Direction)
+	s += fmt.Sprintln("Amount:", a.Amount)
+	s += fmt.Sprintln("Fee:", a.Fee)
+	s += fmt.Sprintln("DBHeight:", a.DBHeight)
+	s += fmt.Sprintln("Timestamp:", a.Timestamp)
+	return s
+}
@@ -0,0 +1,169 @@
+package factom
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ed "github.com/FactomProject/ed25519"
+)
+
+func TestAddressMatcher(t *testing.T) {
+	pub := new([32]byte)
+	from := FctAddressString((&RCD{PubKey: pub[:]}).Hash())
+	to := FctAddressString(make([]byte, 32))
+	ec := ECAddressString(make([]byte, 32))
+
+	tx := NewTransaction()
+	tx.AddInput(pub, 5e8)
+	tx.AddOutput(to, 3e8)
+	tx.AddECOutput(ec, 1e8)
+
+	for addr, want := range map[string]Factoshi{from: 5e8, to: 3e8, ec: 1e8} {
+		match, err := addressMatcher(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a := match(tx)
+		if a == nil {
+			t.Fatalf("%s did not match", addr)
+		}
+		if a.Amount != want {
+			t.Errorf("%s: wrong amount %s", addr, a.Amount)
+		}
+		if (addr == from) != (a.Direction == Sent) {
+			t.Errorf("%s: wrong direction %s", addr, a.Direction)
+		}
+	}
+
+	other := make([]byte, 32)
+	other[0] = 1
+	match, _ := addressMatcher(ECAddressString(other))
+	if match(tx) != nil {
+		t.Error("unrelated address matched")
+	}
+}
+
+// fblockServer serves a Factoid chain from the factomd v1 api. Block i is at
+// Directory Block height i.
+type fblockServer struct {
+	*httptest.Server
+	blocks map[string][]byte
+	head   string
+}
+
+func newFBlockServer(t *testing.T) *fblockServer {
+	s := &fblockServer{blocks: make(map[string][]byte), head: ZeroHash}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arg := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v1/chain-head/"):
+			fmt.Fprintf(w, `{"ChainHead":"%s"}`, s.head)
+		case strings.HasPrefix(r.URL.Path, "/v1/get-raw-data/"):
+			p, ok := s.blocks[arg]
+			if !ok {
+				http.Error(w, "Block not found", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintf(w, `{"Data":"%x"}`, p)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	old := server
+	SetServer(strings.TrimPrefix(s.URL, "http://"))
+	t.Cleanup(func() { SetServer(old) })
+	return s
+}
+
+// add appends a Factoid Block with the Transactions.
+func (s *fblockServer) add(t *testing.T, txs ...*Transaction) {
+	f := new(FBlock)
+	f.BodyMR = ZeroHash
+	f.PrevKeyMR = s.head
+	f.PrevLedgerKeyMR = ZeroHash
+	f.ExchRate = 1000
+	f.DBHeight = uint32(len(s.blocks))
+	f.Transactions = txs
+	for i := range f.EndOfMinute {
+		f.EndOfMinute[i] = len(txs)
+	}
+
+	p, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.head = fmt.Sprintf("f%063x", len(s.blocks))
+	s.blocks[s.head] = p
+}
+
+func TestAddressHistorySince(t *testing.T) {
+	s := newFBlockServer(t)
+
+	pub, pri, err := ed.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := FctAddressString((&RCD{PubKey: pub[:]}).Hash())
+	to := FctAddressString(make([]byte, 32))
+	pay := func(amount Factoshi) *Transaction {
+		tx := NewTransaction()
+		tx.AddInput(pub, amount+12000)
+		tx.AddOutput(to, amount)
+		if err := tx.Sign(pri); err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+	coinbase := NewTransaction()
+	coinbase.AddOutput(to, 5e8)
+
+	s.add(t, coinbase, pay(1e8))
+	s.add(t)
+	s.add(t, pay(2e8))
+
+	ts, cp, err := AddressHistorySince(to, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 3 || ts[0].Amount != 5e8 || ts[0].Fee != 0 || ts[2].Amount != 2e8 || ts[2].Fee != 12000 {
+		t.Errorf("wrong history %v", ts)
+	}
+	if cp == nil || cp.DBHeight != 2 || cp.KeyMR != s.head {
+		t.Fatalf("wrong checkpoint %v", cp)
+	}
+
+	// nothing new keeps the checkpoint
+	ts, cp2, err := AddressHistorySince(to, cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 0 || cp2 == nil || *cp2 != *cp {
+		t.Fatalf("wrong history %v and checkpoint %v with no new blocks", ts, cp2)
+	}
+
+	s.add(t, pay(3e8))
+	ts, cp, err = AddressHistorySince(from, cp2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 1 || ts[0].Direction != Sent || ts[0].Amount != 3e8+12000 || ts[0].DBHeight != 3 {
+		t.Errorf("wrong history since the checkpoint %v", ts)
+	}
+	if cp.DBHeight != 3 {
+		t.Errorf("wrong checkpoint %v", cp)
+	}
+
+	ts, err = AddressHistory(from, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 1 || ts[0].Amount != 2e8+12000 {
+		t.Errorf("wrong history in the height range %v", ts)
+	}
+}
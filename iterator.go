@@ -0,0 +1,402 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ChainIterator walks the Entries of a Chain one at a time, oldest first or
+// newest first, fetching each Entry only when Next reaches it. Stopping early
+// is simply not calling Next again.
+//
+//	it := factom.NewChainIterator(chainid)
+//	for it.Next() {
+//		e := it.Entry()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ChainIterator struct {
+	src      Source
+	chainid  string
+	reverse  bool
+	startKey string
+	startSeq int
+	asOf     int
+
+	// withPending adds the pending Entries of the Chain, which are left
+	// to visit in pending, the next at the end, after the Entries in
+	// Entry Blocks, or before them walking backward. tail is set once the
+	// forward walk has reached them, and pend is the current one.
+	withPending bool
+	pending     []*PendingEntry
+	tail        bool
+	pend        *PendingEntry
+
+	started bool
+	stopped bool
+	err     error
+
+	// filter, if set, decides from the Entry Block whether each Entry is
+	// fetched, skipped, or ends the walk.
+	filter func(EBEntry) filterAction
+
+	// blocks are the Entry Blocks left to visit when walking forward, the
+	// next at the end, and are fetched when reached if only the KeyMR is
+	// set. Walking backward, next is the KeyMR of the next Entry Block to
+	// fetch unless loaded is already fetched.
+	blocks []*chainBlock
+	next   string
+	loaded *chainBlock
+
+	block *chainBlock
+	index int
+	entry *Entry
+}
+
+type chainBlock struct {
+	keymr string
+	eb    *EBlock
+
+	// dbheight is looked up when first needed
+	dbheight int
+	looked   bool
+}
+
+type filterAction int
+
+const (
+	filterFetch filterAction = iota
+	filterSkip
+	filterStop
+)
+
+// NewChainIterator returns an iterator over the Entries of the Chain, oldest
+// first. The Entry Blocks are all fetched by the first call to Next, since they
+// are linked newest to oldest, but the Entries are fetched as they are reached.
+func NewChainIterator(chainid string) *ChainIterator {
+	return &ChainIterator{src: Network, chainid: chainid, startSeq: -1, asOf: -1}
+}
+
+// NewReverseChainIterator returns an iterator over the Entries of the Chain,
+// newest first, fetching the Entry Blocks and Entries as they are reached.
+func NewReverseChainIterator(chainid string) *ChainIterator {
+	return &ChainIterator{src: Network, chainid: chainid, startSeq: -1, asOf: -1, reverse: true}
+}
+
+// UseSource reads the Chain from the Source instead of the Network. It must be
+// called before Next.
+func (it *ChainIterator) UseSource(src Source) *ChainIterator {
+	it.src = src
+	return it
+}
+
+// FromKeyMR starts the iterator at the Entry Block with the KeyMR instead of
+// the first or latest Entry Block. It must be called before Next.
+func (it *ChainIterator) FromKeyMR(keymr string) *ChainIterator {
+	it.startKey = keymr
+	return it
+}
+
+// FromSequence starts the iterator at the Entry Block with the sequence number
+// instead of the first or latest Entry Block. It must be called before Next.
+func (it *ChainIterator) FromSequence(seq int) *ChainIterator {
+	it.startSeq = seq
+	return it
+}
+
+// AsOf walks the Chain as it was at the Directory Block height, ending, or
+// starting if walking backward, at the head the Chain had then. It must be
+// called before Next.
+func (it *ChainIterator) AsOf(height int) *ChainIterator {
+	it.asOf = height
+	return it
+}
+
+// WithPending adds the Entries revealed to the Chain but not yet in a
+// Directory Block as an unconfirmed tail, after the latest Entry, or before it
+// walking backward. Their records have Pending set, and those factomd does not
+// serve yet are skipped. They are left out when walking from an earlier Entry
+// Block backward or as of a height. It must be called before Next.
+func (it *ChainIterator) WithPending() *ChainIterator {
+	it.withPending = true
+	return it
+}
+
+// Next advances to the next Entry and reports whether there is one. It returns
+// false at the end of the Chain or after an error.
+func (it *ChainIterator) Next() bool {
+	if it.err != nil || it.stopped {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		if it.err = it.start(); it.err != nil {
+			return false
+		}
+	}
+
+	// walking backward the pending Entries come first, then the Chain
+	if it.reverse && len(it.pending) > 0 {
+		if it.nextPending() || it.err != nil {
+			return it.err == nil
+		}
+	}
+	if !it.tail {
+		if it.nextConfirmed() {
+			return true
+		}
+		if it.err != nil || it.stopped || it.reverse || !it.withPending || it.asOf >= 0 {
+			return false
+		}
+		it.tail = true
+		if it.err = it.loadPending(); it.err != nil {
+			return false
+		}
+	}
+	return it.nextPending()
+}
+
+// nextConfirmed advances to the next Entry in an Entry Block.
+func (it *ChainIterator) nextConfirmed() bool {
+	it.pend = nil
+	for {
+		if it.block != nil {
+			if it.reverse {
+				it.index--
+			} else {
+				it.index++
+			}
+			if list := it.block.eb.EntryList; it.index >= 0 && it.index < len(list) {
+				if it.filter != nil {
+					switch it.filter(list[it.index]) {
+					case filterSkip:
+						continue
+					case filterStop:
+						it.stopped = true
+						it.block, it.entry = nil, nil
+						return false
+					}
+				}
+				it.entry, it.err = it.src.GetEntry(list[it.index].EntryHash)
+				return it.err == nil
+			}
+		}
+
+		b, err := it.nextBlock()
+		if err != nil || b == nil {
+			it.err = err
+			it.block, it.entry = nil, nil
+			return false
+		}
+		it.block = b
+		it.index = -1
+		if it.reverse {
+			it.index = len(b.eb.EntryList)
+		}
+	}
+}
+
+// Entry returns the current Entry.
+func (it *ChainIterator) Entry() *Entry {
+	return it.entry
+}
+
+// EBlock returns the Entry Block of the current Entry.
+func (it *ChainIterator) EBlock() *EBlock {
+	if it.block == nil {
+		return nil
+	}
+	return it.block.eb
+}
+
+// KeyMR returns the KeyMR of the Entry Block of the current Entry.
+func (it *ChainIterator) KeyMR() string {
+	if it.block == nil {
+		return ""
+	}
+	return it.block.keymr
+}
+
+// nextPending advances to the next pending Entry and reports whether there is
+// one. Pending Entries factomd answers it does not have, as it need not serve
+// Entries before they are in a block, are skipped.
+func (it *ChainIterator) nextPending() bool {
+	it.block = nil
+	it.index = -1
+	for n := len(it.pending); n > 0; n-- {
+		it.pend = it.pending[n-1]
+		it.pending = it.pending[:n-1]
+		e, err := it.src.GetEntry(it.pend.EntryHash)
+		if err == nil {
+			it.entry = e
+			return true
+		}
+		if !errors.Is(err, ErrEntryNotFound) {
+			it.err = err
+			break
+		}
+	}
+	it.pend, it.entry = nil, nil
+	return false
+}
+
+// loadPending fetches the pending Entries of the Chain from factomd.
+func (it *ChainIterator) loadPending() error {
+	ps, err := GetPendingEntries(it.chainid)
+	if err != nil {
+		return err
+	}
+	it.pending = make([]*PendingEntry, 0, len(ps))
+	for _, p := range ps {
+		if p.Status != AckDBlockConfirmed {
+			it.pending = append(it.pending, p)
+		}
+	}
+	if !it.reverse {
+		for i, j := 0, len(it.pending)-1; i < j; i, j = i+1, j-1 {
+			it.pending[i], it.pending[j] = it.pending[j], it.pending[i]
+		}
+	}
+	return nil
+}
+
+// Record returns the current Entry with where and when it was recorded. The
+// Directory Block height is looked up once per Entry Block, from the Source
+// if it knows it, such as a Mirror, or from the factomd v2 api.
+func (it *ChainIterator) Record() *EntryRecord {
+	if it.pend != nil && it.entry != nil {
+		return newPendingRecord(it.entry, it.pend)
+	}
+	b := it.block
+	if b == nil || it.entry == nil {
+		return nil
+	}
+	if !b.looked {
+		b.looked = true
+		b.dbheight = -1
+		if src, ok := it.src.(dbHeighter); ok {
+			if h, ok := src.EBlockDBHeight(b.keymr); ok {
+				b.dbheight = h
+			}
+		}
+	}
+	return newEntryRecord(it.entry, b.keymr, b.eb, it.index, b.dbheight)
+}
+
+// Index returns the position of the current Entry in its Entry Block.
+func (it *ChainIterator) Index() int {
+	return it.index
+}
+
+// Err returns the error that stopped the iterator, if any.
+func (it *ChainIterator) Err() error {
+	return it.err
+}
+
+// start finds the first Entry Block to visit.
+func (it *ChainIterator) start() error {
+	var head *ChainHead
+	var err error
+	if it.asOf >= 0 {
+		head, err = chainHeadAt(it.src, it.chainid, it.asOf)
+	} else {
+		head, err = it.src.GetChainHead(it.chainid)
+	}
+	if err != nil {
+		return err
+	}
+
+	if it.reverse {
+		if it.withPending && it.startKey == "" && it.startSeq < 0 && it.asOf < 0 {
+			if err := it.loadPending(); err != nil {
+				return err
+			}
+		}
+		it.next = head.ChainHead
+		if it.startKey != "" {
+			it.next = it.startKey
+		}
+		if it.startSeq >= 0 {
+			for it.next != ZeroHash {
+				b, err := it.fetch(it.next)
+				if err != nil {
+					return err
+				}
+				it.next = b.eb.Header.PrevKeyMR
+				if b.eb.Header.BlockSequenceNumber <= it.startSeq {
+					it.loaded = b
+					break
+				}
+			}
+		}
+		return nil
+	}
+
+	it.blocks = make([]*chainBlock, 0)
+	for keymr := head.ChainHead; keymr != ZeroHash; {
+		b, err := it.fetch(keymr)
+		if err != nil {
+			return err
+		}
+		it.blocks = append(it.blocks, b)
+		if keymr == it.startKey ||
+			(it.startSeq >= 0 && b.eb.Header.BlockSequenceNumber <= it.startSeq) {
+			return nil
+		}
+		keymr = b.eb.Header.PrevKeyMR
+	}
+	if it.startKey != "" {
+		return fmt.Errorf("Entry Block %s is not in Chain %s", it.startKey, it.chainid)
+	}
+	return nil
+}
+
+// nextBlock returns the next Entry Block to visit, or nil at the end of the
+// Chain.
+func (it *ChainIterator) nextBlock() (*chainBlock, error) {
+	if !it.reverse {
+		n := len(it.blocks)
+		if n == 0 {
+			return nil, nil
+		}
+		b := it.blocks[n-1]
+		it.blocks = it.blocks[:n-1]
+		if b.eb == nil {
+			// the block is known only by KeyMR, as from an EBlockIndex
+			return it.fetch(b.keymr)
+		}
+		return b, nil
+	}
+
+	if b := it.loaded; b != nil {
+		it.loaded = nil
+		return b, nil
+	}
+	if it.next == ZeroHash {
+		return nil, nil
+	}
+	b, err := it.fetch(it.next)
+	if err != nil {
+		return nil, err
+	}
+	it.next = b.eb.Header.PrevKeyMR
+	return b, nil
+}
+
+func (it *ChainIterator) fetch(keymr string) (*chainBlock, error) {
+	eb, err := it.src.GetEBlock(keymr)
+	if err != nil {
+		return nil, err
+	}
+	if id := eb.Header.ChainID; id != "" && id != it.chainid {
+		return nil, fmt.Errorf("Entry Block %s is in Chain %s not %s",
+			keymr, id, it.chainid)
+	}
+	return &chainBlock{keymr: keymr, eb: eb}, nil
+}
@@ -0,0 +1,88 @@
+package factom_test
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+func contents(t *testing.T, it *factom.ChainIterator, n int) string {
+	var s string
+	for i := 0; (n < 0 || i < n) && it.Next(); i++ {
+		s += string(it.Entry().Content)
+	}
+	if err := it.Err(); err != nil {
+		t.Error(err)
+	}
+	return s
+}
+
+func TestChainIterator(t *testing.T) {
+	c := newTestChain(t)
+	c.add("a", "b")
+	c.add()
+	c.add("c")
+	c.add("d", "e")
+
+	for _, v := range []struct {
+		it   *factom.ChainIterator
+		want string
+	}{
+		{factom.NewChainIterator(c.ChainID), "abcde"},
+		{factom.NewReverseChainIterator(c.ChainID), "edcba"},
+		{factom.NewChainIterator(c.ChainID).FromSequence(2), "cde"},
+		{factom.NewReverseChainIterator(c.ChainID).FromSequence(2), "cba"},
+		{factom.NewChainIterator(c.ChainID).FromKeyMR(c.KeyMRs[1]), "cde"},
+		{factom.NewReverseChainIterator(c.ChainID).FromKeyMR(c.KeyMRs[0]), "ba"},
+	} {
+		if s := contents(t, v.it, -1); s != v.want {
+			t.Errorf("iterated %q not %q", s, v.want)
+		}
+	}
+
+	// Entries are only fetched as they are reached
+	gets := c.Gets()
+	it := factom.NewReverseChainIterator(c.ChainID)
+	if s := contents(t, it, 2); s != "ed" {
+		t.Errorf("iterated %q", s)
+	}
+	if n := c.Gets() - gets; n != 2 {
+		t.Errorf("%d Entries fetched for 2", n)
+	}
+	if it.KeyMR() != c.KeyMRs[3] || it.Index() != 0 {
+		t.Errorf("wrong position %s %d", it.KeyMR(), it.Index())
+	}
+
+	it = factom.NewChainIterator(c.ChainID).FromKeyMR(c.ChainID)
+	if it.Next() || it.Err() == nil {
+		t.Error("iterated from an Entry Block not in the Chain")
+	}
+
+	e, err := factom.GetFirstEntry(c.ChainID)
+	if err != nil || string(e.Content) != "a" {
+		t.Errorf("wrong first Entry %v", err)
+	}
+}
+
+func TestChainIteratorRecord(t *testing.T) {
+	c := newTestChain(t)
+	c.add("a")
+	c.tick()
+	c.add("b", "c", "d")
+
+	it := factom.NewReverseChainIterator(c.ChainID)
+	if !it.Next() || !it.Next() {
+		t.Fatal(it.Err())
+	}
+	r := it.Record()
+	if string(r.Entry.Content) != "c" || r.EBlockKeyMR != c.KeyMRs[1] ||
+		r.EBlockSequence != 1 || r.Index != 1 || r.Minute != 1 || r.DBHeight != 2 ||
+		!r.Timestamp.Equal(time.Unix(1260, 0)) {
+		t.Errorf("wrong record\n%s", r)
+	}
+	if r.EntryHash != hex.EncodeToString(r.Entry.Hash()) {
+		t.Errorf("wrong Entry hash %s", r.EntryHash)
+	}
+}
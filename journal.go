@@ -0,0 +1,268 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Journal states of a write
+const (
+	JournalCommitting = "committing"
+	JournalCommitted  = "committed"
+	JournalRevealed   = "revealed"
+	JournalFailed     = "failed"
+)
+
+var errCommitNotSent = errors.New("Commit was never seen by factomd")
+
+// JournalRecord is one line of a Journal. Entry is the hex encoded binary
+// Entry and is set on the committing record so the Entry can be revealed
+// after a restart.
+type JournalRecord struct {
+	Time       time.Time
+	State      string
+	Chain      bool `json:",omitempty"`
+	CommitTxID string
+	EntryHash  string
+	Entry      string `json:",omitempty"`
+	Error      string `json:",omitempty"`
+}
+
+// Journal is an append only write-ahead log of the Entries and Chains written
+// by a Writer. Every write is recorded with its Entry before the commit is
+// sent, and again after the commit and after the reveal, so that reveals
+// interrupted by a crash can be replayed.
+type Journal struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// OpenJournal opens or creates the Journal file at path.
+func OpenJournal(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{f: f}, nil
+}
+
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.f.Close()
+}
+
+// Records returns every record in the Journal in the order written.
+func (j *Journal) Records() ([]*JournalRecord, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.f.Seek(0, 0); err != nil {
+		return nil, err
+	}
+
+	rs := make([]*JournalRecord, 0)
+	s := bufio.NewScanner(j.f)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		r := new(JournalRecord)
+		if err := json.Unmarshal(s.Bytes(), r); err != nil {
+			// a crash may leave a partial last line
+			continue
+		}
+		rs = append(rs, r)
+	}
+	return rs, s.Err()
+}
+
+// Outstanding returns the committing records of the writes that have not yet
+// been revealed or failed, with State set to the latest state of the write. A
+// write still in the committing State may have crashed before its commit was
+// sent.
+func (j *Journal) Outstanding() ([]*JournalRecord, error) {
+	rs, err := j.Records()
+	if err != nil {
+		return nil, err
+	}
+
+	open := make(map[string]*JournalRecord)
+	order := make([]string, 0)
+	for _, r := range rs {
+		switch r.State {
+		case JournalCommitting:
+			if _, ok := open[r.CommitTxID]; !ok {
+				order = append(order, r.CommitTxID)
+			}
+			open[r.CommitTxID] = r
+		case JournalCommitted:
+			if o, ok := open[r.CommitTxID]; ok {
+				o.State = r.State
+			}
+		case JournalRevealed, JournalFailed:
+			delete(open, r.CommitTxID)
+		}
+	}
+
+	out := make([]*JournalRecord, 0)
+	for _, id := range order {
+		if r, ok := open[id]; ok {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// Replay reveals every outstanding write using the Timeout and RetryInterval
+// of the Writer. Writes whose Entry is already in the network are recorded as
+// revealed without sending them again. A write whose commit factomd has never
+// seen, as the crash came before it was sent, is recorded as failed and
+// committed again through the Writer. Writes whose reveal times out have been
+// paid for, so they are recorded as still committed, with the error, and stay
+// outstanding for the next Replay. Writes whose reveal is refused for good are
+// recorded as failed.
+func (j *Journal) Replay(w *Writer) ([]*WriteResult, error) {
+	rs, err := j.Outstanding()
+	if err != nil {
+		return nil, err
+	}
+
+	rw := *w
+	rw.Journal = j
+
+	results := make([]*WriteResult, 0)
+	for _, r := range rs {
+		e, err := r.entry()
+		if err != nil {
+			return results, err
+		}
+
+		c := &Chain{ChainID: e.ChainID, FirstEntry: e}
+		reveal := func() error { return RevealEntry(e) }
+		if r.Chain {
+			reveal = func() error { return RevealChain(c) }
+		}
+		p := newPendingWrite(e, r.CommitTxID, reveal)
+
+		ok, err := entryExists(r.EntryHash)
+		if err != nil {
+			return results, err
+		}
+		if ok {
+			p.result.Status = WriteAlreadyRevealed
+			if err := rw.journal(p.result, JournalRevealed, nil); err != nil {
+				return results, err
+			}
+			results = append(results, p.result)
+			continue
+		}
+
+		if r.State == JournalCommitting {
+			s, err := CommitAck(r.CommitTxID)
+			if err != nil {
+				return results, err
+			}
+			if s.CommitStatus == AckUnknown {
+				if err := rw.journal(p.result, JournalFailed, errCommitNotSent); err != nil {
+					return results, err
+				}
+				if r.Chain {
+					p, err = rw.commitChain(c)
+				} else {
+					p, err = rw.commitEntry(e)
+				}
+				if err != nil {
+					return results, err
+				}
+			}
+		}
+
+		res, err := rw.reveal(p)
+		if err != nil && res.Status == WriteRevealTimeout {
+			if jerr := rw.journal(res, JournalCommitted, err); jerr != nil {
+				return results, jerr
+			}
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// begin records a write and its Entry before the commit is sent.
+func (j *Journal) begin(r *WriteResult, e *Entry, chain bool) error {
+	p, err := e.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return j.append(&JournalRecord{
+		Time:       time.Now(),
+		State:      JournalCommitting,
+		Chain:      chain,
+		CommitTxID: r.CommitTxID,
+		EntryHash:  r.EntryHash,
+		Entry:      hex.EncodeToString(p),
+	})
+}
+
+// record records the new state of a write.
+func (j *Journal) record(r *WriteResult, state string, err error) error {
+	rec := &JournalRecord{
+		Time:       time.Now(),
+		State:      state,
+		CommitTxID: r.CommitTxID,
+		EntryHash:  r.EntryHash,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	return j.append(rec)
+}
+
+// append writes the record and syncs it to disk.
+func (j *Journal) append(r *JournalRecord) error {
+	p, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.f.Write(append(p, '\n')); err != nil {
+		return err
+	}
+	return j.f.Sync()
+}
+
+func (r *JournalRecord) entry() (*Entry, error) {
+	p, err := hex.DecodeString(r.Entry)
+	if err != nil {
+		return nil, err
+	}
+	e := NewEntry()
+	if err := e.UnmarshalBinary(p); err != nil {
+		return nil, fmt.Errorf("Journal Entry %s: %s", r.EntryHash, err)
+	}
+	return e, nil
+}
+
+func (r *JournalRecord) String() string {
+	var s string
+	s += fmt.Sprintln("Time:", r.Time)
+	s += fmt.Sprintln("State:", r.State)
+	s += fmt.Sprintln("CommitTxID:", r.CommitTxID)
+	s += fmt.Sprintln("EntryHash:", r.EntryHash)
+	if r.Error != "" {
+		s += fmt.Sprintln("Error:", r.Error)
+	}
+	return s
+}
@@ -0,0 +1,153 @@
+package factom_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestJournalReplay(t *testing.T) {
+	revealOK := false
+	newTestServer(t, map[string]http.HandlerFunc{
+		"/v1/commit-entry/":  nil,
+		"/v1/entry-by-hash/": entryNotFound,
+		"/v1/reveal-entry/": func(w http.ResponseWriter, r *http.Request) {
+			if !revealOK {
+				http.NotFound(w, r)
+			}
+		},
+	})
+
+	path := filepath.Join(t.TempDir(), "journal")
+	j, err := factom.OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := factom.NewEntry()
+	if err := e.UnmarshalJSON(jsonentry); err != nil {
+		t.Error(err)
+	}
+
+	// the commit succeeds but the reveal does not
+	w := factom.NewWriter("app")
+	w.Journal = j
+	w.Timeout = 5 * time.Millisecond
+	w.RetryInterval = time.Millisecond
+	if _, err := w.WriteEntry(e); err == nil {
+		t.Fatal("reveal did not fail")
+	}
+	j.Close()
+
+	// reopen as though after a restart
+	j, err = factom.OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	rs, err := j.Outstanding()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 1 {
+		t.Fatalf("%d outstanding writes", len(rs))
+	}
+
+	// a reveal that times out again stays outstanding
+	results, err := j.Replay(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Status != factom.WriteRevealTimeout {
+		t.Errorf("wrong replay results %v", results)
+	}
+	if rs, _ := j.Outstanding(); len(rs) != 1 {
+		t.Fatalf("%d writes outstanding after a timed out replay", len(rs))
+	}
+
+	revealOK = true
+	results, err = j.Replay(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Status != factom.WriteRevealed {
+		t.Errorf("wrong replay results %v", results)
+	}
+	if rs, _ := j.Outstanding(); len(rs) != 0 {
+		t.Errorf("%d writes outstanding after replay", len(rs))
+	}
+}
+
+func TestJournalReplayUnsentCommit(t *testing.T) {
+	commits := 0
+	newTestServer(t, map[string]http.HandlerFunc{
+		"/v1/commit-entry/": func(w http.ResponseWriter, r *http.Request) {
+			commits++
+		},
+		"/v1/entry-by-hash/": entryNotFound,
+		"/v1/reveal-entry/":  nil,
+		"/v2": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":{"commitdata":{"status":"Unknown"}}}`))
+		},
+	})
+
+	e := factom.NewEntry()
+	if err := e.UnmarshalJSON(jsonentry); err != nil {
+		t.Error(err)
+	}
+	c, err := factom.NewEntryCommit(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := e.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a crash before the commit was sent leaves only the committing record
+	path := filepath.Join(t.TempDir(), "journal")
+	rec, err := json.Marshal(&factom.JournalRecord{
+		State:      factom.JournalCommitting,
+		CommitTxID: c.TxID(),
+		EntryHash:  hex.EncodeToString(e.Hash()),
+		Entry:      hex.EncodeToString(p),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, append(rec, '\n'), 0600); err != nil {
+		t.Fatal(err)
+	}
+	j, err := factom.OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	w := factom.NewWriter("app")
+	w.RetryInterval = time.Millisecond
+	results, err := j.Replay(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Status != factom.WriteRevealed || commits != 1 {
+		t.Errorf("wrong replay results after %d commits %v", commits, results)
+	}
+
+	rs, err := j.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) < 2 || rs[1].CommitTxID != c.TxID() || rs[1].State != factom.JournalFailed {
+		t.Errorf("unsent commit was not recorded as failed\n%v", rs)
+	}
+	if rs, _ := j.Outstanding(); len(rs) != 0 {
+		t.Errorf("%d writes outstanding after replay", len(rs))
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// JSONError is an error returned by the factomd v2 JSON-RPC api.
+type JSONError struct {
+	Code    int
+	Message string
+	Data    interface{}
+}
+
+func (e *JSONError) Error() string {
+	if e.Data != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Data)
+	}
+	return e.Message
+}
+
+// factomdRequest calls the factomd v2 JSON-RPC method and decodes the result
+// into result.
+func factomdRequest(method string, params, result interface{}) error {
+	type request struct {
+		JSONRPC string      `json:"jsonrpc"`
+		ID      int         `json:"id"`
+		Method  string      `json:"method"`
+		Params  interface{} `json:"params,omitempty"`
+	}
+	type response struct {
+		Result json.RawMessage
+		Error  *JSONError
+	}
+
+	j, err := json.Marshal(&request{"2.0", 0, method, params})
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Post(
+		fmt.Sprintf("http://%s/v2", server),
+		"application/json",
+		bytes.NewBuffer(j))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	r := new(response)
+	if err := json.Unmarshal(body, r); err != nil {
+		return fmt.Errorf("%s: %s\n", err, body)
+	}
+	if r.Error != nil {
+		return r.Error
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(r.Result, result)
+}
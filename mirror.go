@@ -0,0 +1,357 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Mirror record types
+const (
+	mirrorChain  = "chain"
+	mirrorEntry  = "entry"
+	mirrorEBlock = "eblock"
+)
+
+// mirrorRecord is one line of the Mirror file. A chain record sets the synced
+// head of a Chain, an empty Head meaning the Chain is mirrored but not yet
+// synced.
+type mirrorRecord struct {
+	Type     string
+	ChainID  string  `json:",omitempty"`
+	Head     string  `json:",omitempty"`
+	Hash     string  `json:",omitempty"`
+	Entry    string  `json:",omitempty"`
+	KeyMR    string  `json:",omitempty"`
+	DBHeight int     `json:",omitempty"`
+	EBlock   *EBlock `json:",omitempty"`
+}
+
+type mirrorEBlockRef struct {
+	eb       *EBlock
+	dbheight int
+}
+
+type mirrorEntryRef struct {
+	off int64
+	n   int
+}
+
+// Mirror is a local copy of a set of Chains kept in a single append only file.
+// Sync brings the copy up to date, fetching only the Entry Blocks after the
+// last synced head of each Chain, and the Mirror is a Source that answers from
+// the copy when it has the data and from the Network otherwise.
+//
+// The Entry Blocks and the offsets of the Entries are held in memory, and the
+// Entries are read from the file as needed.
+type Mirror struct {
+	mu      sync.RWMutex
+	f       *os.File
+	size    int64
+	heads   map[string]string
+	eblocks map[string]*mirrorEBlockRef
+	entries map[string]*mirrorEntryRef
+}
+
+// OpenMirror opens or creates the Mirror file at path.
+func OpenMirror(path string) (*Mirror, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	m := new(Mirror)
+	m.f = f
+	m.heads = make(map[string]string)
+	m.eblocks = make(map[string]*mirrorEBlockRef)
+	m.entries = make(map[string]*mirrorEntryRef)
+	if err := m.load(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *Mirror) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.f.Close()
+}
+
+// load reads the index of the Mirror file. A crash may leave a partial last
+// line, which is cut off so that later records start on a line of their own.
+func (m *Mirror) load() error {
+	r := bufio.NewReader(m.f)
+	var off int64
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			m.size = off
+			if len(line) > 0 {
+				return m.f.Truncate(off)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		rec := new(mirrorRecord)
+		if err := json.Unmarshal(line, rec); err != nil {
+			return fmt.Errorf("Mirror record at %d: %s", off, err)
+		}
+		m.index(rec, off, len(line))
+		off += int64(len(line))
+	}
+}
+
+// index adds the record at the offset to the in memory index.
+func (m *Mirror) index(rec *mirrorRecord, off int64, n int) {
+	switch rec.Type {
+	case mirrorChain:
+		m.heads[rec.ChainID] = rec.Head
+	case mirrorEBlock:
+		m.eblocks[rec.KeyMR] = &mirrorEBlockRef{rec.EBlock, rec.DBHeight}
+	case mirrorEntry:
+		m.entries[rec.Hash] = &mirrorEntryRef{off, n}
+	}
+}
+
+// append writes the records and adds them to the index. The caller must hold
+// the write lock.
+func (m *Mirror) append(recs ...*mirrorRecord) error {
+	buf := make([]byte, 0)
+	offs := make([]int, 0, len(recs))
+	for _, rec := range recs {
+		p, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		offs = append(offs, len(buf))
+		buf = append(append(buf, p...), '\n')
+	}
+
+	if _, err := m.f.Write(buf); err != nil {
+		return err
+	}
+	if err := m.f.Sync(); err != nil {
+		return err
+	}
+
+	for i, rec := range recs {
+		end := len(buf)
+		if i+1 < len(offs) {
+			end = offs[i+1]
+		}
+		m.index(rec, m.size+int64(offs[i]), end-offs[i])
+	}
+	m.size += int64(len(buf))
+	return nil
+}
+
+// Add starts mirroring the Chains. Their Entries are copied by the next Sync.
+func (m *Mirror) Add(chainids ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	recs := make([]*mirrorRecord, 0)
+	for _, id := range chainids {
+		if _, ok := m.heads[id]; !ok {
+			recs = append(recs, &mirrorRecord{Type: mirrorChain, ChainID: id})
+		}
+	}
+	return m.append(recs...)
+}
+
+// Chains returns the ChainIDs of the mirrored Chains.
+func (m *Mirror) Chains() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make([]string, 0, len(m.heads))
+	for id := range m.heads {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Sync copies the new Entry Blocks and Entries of every mirrored Chain.
+func (m *Mirror) Sync() error {
+	for _, id := range m.Chains() {
+		if err := m.SyncChain(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncChain copies the Entry Blocks and Entries of the Chain after its last
+// synced head, oldest first. The head is recorded after each Entry Block so an
+// interrupted sync resumes from the last complete Entry Block.
+func (m *Mirror) SyncChain(chainid string) error {
+	m.mu.RLock()
+	stop, ok := m.heads[chainid]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("Chain %s is not mirrored", chainid)
+	}
+	if stop == "" {
+		stop = ZeroHash
+	}
+
+	head, err := GetChainHead(chainid)
+	if err != nil {
+		return err
+	}
+
+	keymrs := make([]string, 0)
+	ebs := make([]*EBlock, 0)
+	for keymr := head.ChainHead; keymr != stop; {
+		if keymr == ZeroHash {
+			return fmt.Errorf("Mirrored head %s is not in Chain %s", stop, chainid)
+		}
+		eb, err := GetEBlock(keymr)
+		if err != nil {
+			return err
+		}
+		keymrs = append(keymrs, keymr)
+		ebs = append(ebs, eb)
+		keymr = eb.Header.PrevKeyMR
+	}
+
+	for i := len(ebs) - 1; i >= 0; i-- {
+		es := make([]*Entry, 0, len(ebs[i].EntryList))
+		for _, v := range ebs[i].EntryList {
+			e, err := GetEntry(v.EntryHash)
+			if err != nil {
+				return err
+			}
+			es = append(es, e)
+		}
+		h, err := GetEBlockDBHeight(keymrs[i])
+		if err != nil {
+			return err
+		}
+		if err := m.putEBlock(chainid, keymrs[i], ebs[i], h, es); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putEBlock records the Entries and the Entry Block and makes the Entry Block
+// the synced head of its Chain.
+func (m *Mirror) putEBlock(chainid, keymr string, eb *EBlock, dbheight int, es []*Entry) error {
+	recs := make([]*mirrorRecord, 0, len(es)+2)
+	for _, e := range es {
+		p, err := e.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		recs = append(recs, &mirrorRecord{
+			Type:  mirrorEntry,
+			Hash:  hex.EncodeToString(e.Hash()),
+			Entry: hex.EncodeToString(p),
+		})
+	}
+	recs = append(recs,
+		&mirrorRecord{Type: mirrorEBlock, KeyMR: keymr, DBHeight: dbheight, EBlock: eb},
+		&mirrorRecord{Type: mirrorChain, ChainID: chainid, Head: keymr})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.append(recs...)
+}
+
+// GetChainHead returns the last synced head of a mirrored Chain, or asks the
+// Network for any other Chain.
+func (m *Mirror) GetChainHead(chainid string) (*ChainHead, error) {
+	m.mu.RLock()
+	head, ok := m.heads[chainid]
+	m.mu.RUnlock()
+	if !ok {
+		return GetChainHead(chainid)
+	}
+	if head == "" {
+		return nil, fmt.Errorf("%w: %s has not been synced", ErrChainNotFound, chainid)
+	}
+	return &ChainHead{head}, nil
+}
+
+// GetEBlock returns the Entry Block from the Mirror if it is there and from
+// the Network otherwise.
+func (m *Mirror) GetEBlock(keymr string) (*EBlock, error) {
+	m.mu.RLock()
+	ref, ok := m.eblocks[keymr]
+	m.mu.RUnlock()
+	if !ok {
+		return GetEBlock(keymr)
+	}
+	return ref.eb, nil
+}
+
+// EBlockDBHeight returns the height of the Directory Block holding a mirrored
+// Entry Block.
+func (m *Mirror) EBlockDBHeight(keymr string) (int, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ref, ok := m.eblocks[keymr]
+	if !ok {
+		return 0, false
+	}
+	return ref.dbheight, true
+}
+
+// GetEntry returns the Entry from the Mirror if it is there and from the
+// Network otherwise.
+func (m *Mirror) GetEntry(hash string) (*Entry, error) {
+	m.mu.RLock()
+	ref, ok := m.entries[hash]
+	if !ok {
+		m.mu.RUnlock()
+		return GetEntry(hash)
+	}
+	line := make([]byte, ref.n)
+	_, err := m.f.ReadAt(line, ref.off)
+	m.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	rec := new(mirrorRecord)
+	if err := json.Unmarshal(line, rec); err != nil {
+		return nil, err
+	}
+	p, err := hex.DecodeString(rec.Entry)
+	if err != nil {
+		return nil, err
+	}
+	e := NewEntry()
+	if err := e.UnmarshalBinary(p); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// NewChainIterator returns an iterator over the Entries of the Chain, oldest
+// first, read from the Mirror.
+func (m *Mirror) NewChainIterator(chainid string) *ChainIterator {
+	return NewChainIterator(chainid).UseSource(m)
+}
+
+// NewReverseChainIterator returns an iterator over the Entries of the Chain,
+// newest first, read from the Mirror.
+func (m *Mirror) NewReverseChainIterator(chainid string) *ChainIterator {
+	return NewReverseChainIterator(chainid).UseSource(m)
+}
@@ -0,0 +1,81 @@
+package factom_test
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestMirror(t *testing.T) {
+	c := newTestChain(t)
+	c.add("a", "b")
+	c.tick()
+	c.add("c")
+
+	path := filepath.Join(t.TempDir(), "mirror")
+	m, err := factom.OpenMirror(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add(c.ChainID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.GetChainHead(c.ChainID); err == nil {
+		t.Error("unsynced Chain has a head")
+	}
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if h, ok := m.EBlockDBHeight(c.KeyMRs[1]); !ok || h != 2 {
+		t.Errorf("wrong Directory Block height %d", h)
+	}
+
+	// only the new Entry Block is fetched by the next sync
+	c.add("d")
+	gets := c.Gets()
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := c.Gets() - gets; n != 1 {
+		t.Errorf("%d Entries fetched for 1 new Entry", n)
+	}
+	m.Close()
+
+	// a partial last record is dropped when the Mirror is reopened
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte(`{"Type":"entry","Ha`))
+	f.Close()
+
+	m, err = factom.OpenMirror(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	c.add("e")
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	gets = c.Gets()
+	if s := contents(t, m.NewChainIterator(c.ChainID), -1); s != "abcde" {
+		t.Errorf("iterated %q from the Mirror", s)
+	}
+	if s := contents(t, m.NewReverseChainIterator(c.ChainID), -1); s != "edcba" {
+		t.Errorf("iterated %q from the Mirror", s)
+	}
+	if n := c.Gets() - gets; n != 0 {
+		t.Errorf("%d Entries fetched from the Network", n)
+	}
+
+	// Entries outside the Mirror come from the Network
+	e := c.add("f")[0]
+	if _, err := m.GetEntry(hex.EncodeToString(e.Hash())); err != nil || c.Gets() != gets+1 {
+		t.Errorf("Entry was not fetched from the Network: %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"fmt"
+)
+
+// PendingEntry is an Entry that has been revealed but is not yet recorded in
+// a Directory Block.
+type PendingEntry struct {
+	EntryHash string
+	ChainID   string
+	Status    AckStatus
+}
+
+// PendingTransaction is a Factoid Transaction that has been submitted but is
+// not yet recorded in a Directory Block.
+type PendingTransaction struct {
+	TxID      string
+	DBHeight  int
+	Status    AckStatus
+	Inputs    []*PendingTransAddress
+	Outputs   []*PendingTransAddress
+	ECOutputs []*PendingTransAddress
+	Fees      Factoshi
+}
+
+// PendingTransAddress is an amount paid from or to the human readable Factoid
+// or Entry Credit address.
+type PendingTransAddress struct {
+	Amount  Factoshi
+	Address string
+}
+
+// GetPendingEntries returns the Entries factomd holds that are not yet in a
+// Directory Block, in the order they were revealed. If chainid is not empty
+// only the Entries in that Chain are returned. A status this library does not
+// know is AckUnknown.
+func GetPendingEntries(chainid string) ([]*PendingEntry, error) {
+	type result struct {
+		EntryHash string `json:"entryhash"`
+		ChainID   string `json:"chainid"`
+		Status    string `json:"status"`
+	}
+
+	rs := make([]result, 0)
+	if err := factomdRequest("pending-entries", nil, &rs); err != nil {
+		return nil, err
+	}
+
+	ps := make([]*PendingEntry, 0)
+	for _, r := range rs {
+		if chainid != "" && r.ChainID != chainid {
+			continue
+		}
+		s, _ := ParseAckStatus(r.Status)
+		ps = append(ps, &PendingEntry{r.EntryHash, r.ChainID, s})
+	}
+	return ps, nil
+}
+
+// GetPendingTransactions returns the Factoid Transactions factomd holds that
+// are not yet in a Directory Block. If address is not empty only the
+// Transactions paying from or to that address are returned. A status this
+// library does not know is AckUnknown.
+func GetPendingTransactions(address string) ([]*PendingTransaction, error) {
+	type params struct {
+		Address string `json:"address,omitempty"`
+	}
+	type transAddress struct {
+		Amount      Factoshi `json:"amount"`
+		UserAddress string   `json:"useraddress"`
+	}
+	type result struct {
+		TxID      string          `json:"transactionid"`
+		DBHeight  int             `json:"dbheight"`
+		Status    string          `json:"status"`
+		Inputs    []*transAddress `json:"inputs"`
+		Outputs   []*transAddress `json:"outputs"`
+		ECOutputs []*transAddress `json:"ecoutputs"`
+		Fees      Factoshi        `json:"fees"`
+	}
+
+	rs := make([]result, 0)
+	if err := factomdRequest("pending-transactions", &params{address}, &rs); err != nil {
+		return nil, err
+	}
+
+	addrs := func(as []*transAddress) []*PendingTransAddress {
+		ps := make([]*PendingTransAddress, 0, len(as))
+		for _, a := range as {
+			ps = append(ps, &PendingTransAddress{a.Amount, a.UserAddress})
+		}
+		return ps
+	}
+
+	ts := make([]*PendingTransaction, 0, len(rs))
+	for _, r := range rs {
+		s, _ := ParseAckStatus(r.Status)
+		t := new(PendingTransaction)
+		t.TxID = r.TxID
+		t.DBHeight = r.DBHeight
+		t.Status = s
+		t.Inputs = addrs(r.Inputs)
+		t.Outputs = addrs(r.Outputs)
+		t.ECOutputs = addrs(r.ECOutputs)
+		t.Fees = r.Fees
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
+// GetPendingEntry returns the Entry with the hash if factomd holds it but it is
+// not yet in a Directory Block, or nil if it is not pending.
+func GetPendingEntry(hash string) (*PendingEntry, error) {
+	ps, err := GetPendingEntries("")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range ps {
+		if p.EntryHash == hash {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetPendingTransaction returns the Factoid Transaction with the TxID if
+// factomd holds it but it is not yet in a Directory Block, or nil if it is not
+// pending.
+func GetPendingTransaction(txid string) (*PendingTransaction, error) {
+	ts, err := GetPendingTransactions("")
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range ts {
+		if t.TxID == txid {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (p *PendingEntry) String() string {
+	var s string
+	s += fmt.Sprintln("EntryHash:", p.EntryHash)
+	s += fmt.Sprintln("ChainID:", p.ChainID)
+	s += fmt.Sprintln("Status:", p.Status)
+	return s
+}
+
+func (t *PendingTransaction) String() string {
+	var s string
+	s += fmt.Sprintln("TxID:", t.TxID)
+	s += fmt.Sprintln("DBHeight:", t.DBHeight)
+	s += fmt.Sprintln("Status:", t.Status)
+	for _, a := range t.Inputs {
+		s += fmt.Sprintln("Input:", a.Address, a.Amount)
+	}
+	for _, a := range t.Outputs {
+		s += fmt.Sprintln("Output:", a.Address, a.Amount)
+	}
+	for _, a := range t.ECOutputs {
+		s += fmt.Sprintln("ECOutput:", a.Address, a.Amount)
+	}
+	s += fmt.Sprintln("Fees:", t.Fees)
+	return s
+}
@@ -0,0 +1,172 @@
+package factom_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestGetPendingEntries(t *testing.T) {
+	c := newTestChain(t)
+	c.reveal("x", "y")
+
+	ps, err := factom.GetPendingEntries("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 3 || ps[2].ChainID != factom.ECChainID || ps[2].Status != factom.AckNotConfirmed {
+		t.Errorf("wrong pending Entries %v", ps)
+	}
+
+	ps, err = factom.GetPendingEntries(c.ChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 2 || ps[0].Status != factom.AckTransactionACK {
+		t.Errorf("wrong pending Entries in the Chain %v", ps)
+	}
+}
+
+func TestChainIteratorPending(t *testing.T) {
+	c := newTestChain(t)
+	c.add("a", "b")
+	c.add("c")
+	c.reveal("x")
+	// factomd does not serve every pending Entry before it is in a block
+	c.mu.Lock()
+	c.pending = append(c.pending, factom.ZeroHash)
+	c.mu.Unlock()
+	c.reveal("y")
+
+	if s := contents(t, factom.NewChainIterator(c.ChainID), -1); s != "abc" {
+		t.Errorf("iterated %q without pending Entries", s)
+	}
+	if s := contents(t, factom.NewChainIterator(c.ChainID).WithPending(), -1); s != "abcxy" {
+		t.Errorf("iterated %q", s)
+	}
+	if s := contents(t, factom.NewReverseChainIterator(c.ChainID).WithPending(), -1); s != "yxcba" {
+		t.Errorf("iterated %q backward", s)
+	}
+	if s := contents(t, factom.NewReverseChainIterator(c.ChainID).FromSequence(0).WithPending(), -1); s != "ba" {
+		t.Errorf("iterated %q backward from the first Entry Block", s)
+	}
+	if s := contents(t, factom.NewChainIterator(c.ChainID).AsOf(1).WithPending(), -1); s != "abc" {
+		t.Errorf("iterated %q as of the latest height", s)
+	}
+
+	it := factom.NewChainIterator(c.ChainID).FromSequence(1).WithPending()
+	for i := 0; it.Next(); i++ {
+		r := it.Record()
+		if pending := i > 0; r.Pending != pending || (r.DBHeight < 0) != pending {
+			t.Errorf("wrong record of Entry %d\n%s", i, r)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	q := factom.NewQuery(c.ChainID, factom.ContentContains([]byte("x"))).WithPending()
+	if !q.Next() || !q.Record().Pending || q.Next() {
+		t.Errorf("Query did not match the pending Entry")
+	}
+}
+
+func TestChainIteratorPendingUnfetchable(t *testing.T) {
+	c := newTestChain(t)
+	c.add("a", "b")
+
+	// the oldest pending Entry is not served before it is in a block
+	c.mu.Lock()
+	c.pending = append(c.pending, factom.ZeroHash)
+	c.mu.Unlock()
+	c.reveal("y")
+
+	if s := contents(t, factom.NewReverseChainIterator(c.ChainID).WithPending(), -1); s != "yba" {
+		t.Errorf("iterated %q backward", s)
+	}
+	if s := contents(t, factom.NewChainIterator(c.ChainID).WithPending(), -1); s != "aby" {
+		t.Errorf("iterated %q", s)
+	}
+
+	// no pending Entry can be fetched
+	c.mu.Lock()
+	c.pending = []string{factom.ZeroHash}
+	c.mu.Unlock()
+	if s := contents(t, factom.NewReverseChainIterator(c.ChainID).WithPending(), -1); s != "ba" {
+		t.Errorf("iterated %q backward without a fetchable pending Entry", s)
+	}
+}
+
+func TestGetPendingTransactions(t *testing.T) {
+	newTestServer(t, map[string]http.HandlerFunc{"/v2": func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":[{
+			"transactionid":"` + factom.ZeroHash + `",
+			"status":"TransactionACK",
+			"inputs":[{"amount":1012000,"address":"00","useraddress":"FA1"}],
+			"outputs":[{"amount":1000000,"address":"00","useraddress":"FA2"}],
+			"ecoutputs":[],
+			"fees":12000}]}`))
+	}})
+
+	txs, err := factom.GetPendingTransactions("FA1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 1 {
+		t.Fatalf("got %d pending Transactions", len(txs))
+	}
+	tx := txs[0]
+	if tx.TxID != factom.ZeroHash || tx.Status != factom.AckTransactionACK || tx.Fees != 12000 ||
+		len(tx.Inputs) != 1 || tx.Inputs[0].Address != "FA1" || tx.Inputs[0].Amount != 1012000 ||
+		len(tx.Outputs) != 1 || tx.Outputs[0].Address != "FA2" || len(tx.ECOutputs) != 0 {
+		t.Errorf("wrong pending Transaction\n%s", tx)
+	}
+}
+
+func TestPendingUnknownStatus(t *testing.T) {
+	newTestServer(t, map[string]http.HandlerFunc{"/v2": func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method == "pending-entries" {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":[
+				{"entryhash":"e1","chainid":"c1","status":"Mystery"},
+				{"entryhash":"e2","chainid":"c1","status":"TransactionACK"}]}`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":[
+			{"transactionid":"t1","status":"Mystery"},
+			{"transactionid":"t2","status":"TransactionACK"}]}`))
+	}})
+
+	ps, err := factom.GetPendingEntries("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 2 || ps[0].Status != factom.AckUnknown || ps[1].Status != factom.AckTransactionACK {
+		t.Errorf("wrong pending Entries %v", ps)
+	}
+	txs, err := factom.GetPendingTransactions("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 || txs[0].Status != factom.AckUnknown || txs[1].Status != factom.AckTransactionACK {
+		t.Errorf("wrong pending Transactions %v", txs)
+	}
+
+	if p, err := factom.GetPendingEntry("e2"); err != nil || p == nil || p.ChainID != "c1" {
+		t.Errorf("GetPendingEntry %v %v", p, err)
+	}
+	if p, err := factom.GetPendingEntry("e3"); err != nil || p != nil {
+		t.Errorf("GetPendingEntry of an Entry not pending %v %v", p, err)
+	}
+	if tx, err := factom.GetPendingTransaction("t1"); err != nil || tx == nil || tx.Status != factom.AckUnknown {
+		t.Errorf("GetPendingTransaction %v %v", tx, err)
+	}
+	if tx, err := factom.GetPendingTransaction("t3"); err != nil || tx != nil {
+		t.Errorf("GetPendingTransaction of a Transaction not pending %v %v", tx, err)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"regexp"
+	"time"
+)
+
+// EntryPredicate reports whether an Entry matches a Query.
+type EntryPredicate func(e *Entry) bool
+
+// ExtIDEquals matches Entries whose ExtID i is v. An i below 0 matches any
+// ExtID.
+func ExtIDEquals(i int, v []byte) EntryPredicate {
+	return extIDMatch(i, func(id []byte) bool { return bytes.Equal(id, v) })
+}
+
+// ExtIDHasPrefix matches Entries whose ExtID i starts with prefix. An i below 0
+// matches any ExtID.
+func ExtIDHasPrefix(i int, prefix []byte) EntryPredicate {
+	return extIDMatch(i, func(id []byte) bool { return bytes.HasPrefix(id, prefix) })
+}
+
+// ExtIDMatches matches Entries whose ExtID i matches the regular expression. An
+// i below 0 matches any ExtID.
+func ExtIDMatches(i int, re *regexp.Regexp) EntryPredicate {
+	return extIDMatch(i, re.Match)
+}
+
+func extIDMatch(i int, match func([]byte) bool) EntryPredicate {
+	return func(e *Entry) bool {
+		if i >= 0 {
+			return i < len(e.ExtIDs) && match(e.ExtIDs[i])
+		}
+		for _, id := range e.ExtIDs {
+			if match(id) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ContentContains matches Entries whose Content contains p.
+func ContentContains(p []byte) EntryPredicate {
+	return func(e *Entry) bool { return bytes.Contains(e.Content, p) }
+}
+
+// ContentMatches matches Entries whose Content matches the regular expression.
+func ContentMatches(re *regexp.Regexp) EntryPredicate {
+	return func(e *Entry) bool { return re.Match(e.Content) }
+}
+
+// And matches Entries matching every predicate.
+func And(ps ...EntryPredicate) EntryPredicate {
+	return func(e *Entry) bool {
+		for _, p := range ps {
+			if !p(e) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or matches Entries matching any of the predicates.
+func Or(ps ...EntryPredicate) EntryPredicate {
+	return func(e *Entry) bool {
+		for _, p := range ps {
+			if p(e) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches Entries not matching the predicate.
+func Not(p EntryPredicate) EntryPredicate {
+	return func(e *Entry) bool { return !p(e) }
+}
+
+// Query scans a Chain for the Entries matching every predicate, returning
+// them one at a time as they are found.
+//
+//	q := factom.NewQuery(chainid, factom.ExtIDEquals(0, []byte("invoice-1234")))
+//	for q.Next() {
+//		r := q.Record()
+//		...
+//	}
+//	if err := q.Err(); err != nil {
+//		...
+//	}
+type Query struct {
+	it     *ChainIterator
+	match  EntryPredicate
+	from   time.Time
+	to     time.Time
+	limit  int
+	found  int
+	record *EntryRecord
+}
+
+// NewQuery returns a Query of the Chain, oldest Entry first.
+func NewQuery(chainid string, ps ...EntryPredicate) *Query {
+	return newQuery(NewChainIterator(chainid), ps)
+}
+
+// NewReverseQuery returns a Query of the Chain, newest Entry first.
+func NewReverseQuery(chainid string, ps ...EntryPredicate) *Query {
+	return newQuery(NewReverseChainIterator(chainid), ps)
+}
+
+func newQuery(it *ChainIterator, ps []EntryPredicate) *Query {
+	q := &Query{it: it, match: And(ps...)}
+	it.filter = q.filter
+	return q
+}
+
+// UseSource scans the Chain from the Source, such as a Mirror, instead of the
+// Network. It must be called before Next.
+func (q *Query) UseSource(src Source) *Query {
+	q.it.UseSource(src)
+	return q
+}
+
+// Between limits the Query to the Entries with timestamps from from up to but
+// not including to. Either may be zero to leave that end open. Entries outside
+// the range are not fetched, and the scan ends once it has passed the range.
+func (q *Query) Between(from, to time.Time) *Query {
+	q.from, q.to = from, to
+	return q
+}
+
+// WithPending also matches the Entries revealed to the Chain but not yet in a
+// Directory Block, as with ChainIterator.WithPending. They have no timestamp,
+// so a Query limited by Between leaves them out.
+func (q *Query) WithPending() *Query {
+	q.it.WithPending()
+	return q
+}
+
+// Limit ends the Query after n results.
+func (q *Query) Limit(n int) *Query {
+	q.limit = n
+	return q
+}
+
+// Next advances to the next matching Entry and reports whether there is one.
+func (q *Query) Next() bool {
+	q.record = nil
+	if q.limit > 0 && q.found >= q.limit {
+		return false
+	}
+
+	for q.it.Next() {
+		e := q.it.Entry()
+		if q.it.pend != nil && !(q.from.IsZero() && q.to.IsZero()) {
+			continue
+		}
+		if !q.match(e) {
+			continue
+		}
+
+		q.found++
+		q.record = q.it.Record()
+		return true
+	}
+	return false
+}
+
+// Record returns the current matching Entry with where and when it was
+// recorded.
+func (q *Query) Record() *EntryRecord {
+	return q.record
+}
+
+// Err returns the error that stopped the Query, if any.
+func (q *Query) Err() error {
+	return q.it.Err()
+}
+
+// filter skips the Entries outside the time range without fetching them, and
+// stops the scan once it has passed the range.
+func (q *Query) filter(v EBEntry) filterAction {
+	t := v.Time()
+	switch {
+	case !q.from.IsZero() && t.Before(q.from):
+		if q.it.reverse {
+			return filterStop
+		}
+		return filterSkip
+	case !q.to.IsZero() && !t.Before(q.to):
+		if q.it.reverse {
+			return filterSkip
+		}
+		return filterStop
+	}
+	return filterFetch
+}
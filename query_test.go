@@ -0,0 +1,92 @@
+package factom_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+func invoice(chainid string, id int, content string) *factom.Entry {
+	e := factom.NewEntry()
+	e.ChainID = chainid
+	e.ExtIDs = [][]byte{[]byte(fmt.Sprint("invoice-", id)), []byte("acme")}
+	e.Content = []byte(content)
+	return e
+}
+
+func query(t *testing.T, q *factom.Query) string {
+	var s string
+	for q.Next() {
+		s += string(q.Record().Entry.Content)
+	}
+	if err := q.Err(); err != nil {
+		t.Error(err)
+	}
+	return s
+}
+
+func TestQuery(t *testing.T) {
+	c := newTestChain(t)
+	c.addEntries(invoice(c.ChainID, 1, "a"), invoice(c.ChainID, 12, "b"))
+	c.addEntries(invoice(c.ChainID, 123, "c"))
+	c.addEntries(invoice(c.ChainID, 1234, "d paid"), invoice(c.ChainID, 2, "e paid"))
+
+	id := func(s string) []byte { return []byte(s) }
+	for _, v := range []struct {
+		q    *factom.Query
+		want string
+	}{
+		{factom.NewQuery(c.ChainID, factom.ExtIDEquals(0, id("invoice-1234"))), "d paid"},
+		{factom.NewQuery(c.ChainID, factom.ExtIDHasPrefix(0, id("invoice-12"))), "bcd paid"},
+		{factom.NewQuery(c.ChainID, factom.ExtIDMatches(-1, regexp.MustCompile(`^invoice-\d$`))), "ae paid"},
+		{factom.NewQuery(c.ChainID, factom.ExtIDEquals(1, id("acme")), factom.ContentContains(id("paid"))), "d paide paid"},
+		{factom.NewQuery(c.ChainID, factom.Or(factom.ContentMatches(regexp.MustCompile(`^[ac]$`)),
+			factom.Not(factom.ExtIDHasPrefix(0, id("invoice"))))), "ac"},
+		{factom.NewReverseQuery(c.ChainID, factom.ExtIDHasPrefix(0, id("invoice-1"))).Limit(2), "d paidc"},
+		{factom.NewQuery(c.ChainID).Between(time.Unix(60, 0), time.Unix(1260, 0)), "bcd paid"},
+		{factom.NewReverseQuery(c.ChainID).Between(time.Unix(60, 0), time.Unix(1260, 0)), "d paidcb"},
+	} {
+		if s := query(t, v.q); s != v.want {
+			t.Errorf("found %q not %q", s, v.want)
+		}
+	}
+
+	// Entries outside the time range are not fetched
+	gets := c.Gets()
+	q := factom.NewQuery(c.ChainID).Between(time.Unix(600, 0), time.Unix(1200, 0))
+	if s := query(t, q); s != "c" {
+		t.Errorf("found %q", s)
+	}
+	if n := c.Gets() - gets; n != 1 {
+		t.Errorf("%d Entries fetched for 1 in range", n)
+	}
+
+	q = factom.NewQuery(c.ChainID, factom.ExtIDEquals(0, id("invoice-123")))
+	if !q.Next() {
+		t.Fatal(q.Err())
+	}
+	if r := q.Record(); r.EBlockKeyMR != c.KeyMRs[1] || r.EBlockSequence != 1 || r.Index != 0 ||
+		!r.Timestamp.Equal(time.Unix(600, 0)) {
+		t.Errorf("wrong position\n%s", r)
+	}
+
+	// through a Mirror
+	m, err := factom.OpenMirror(filepath.Join(t.TempDir(), "mirror"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	m.Add(c.ChainID)
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	gets = c.Gets()
+	q = factom.NewQuery(c.ChainID, factom.ContentContains(id("paid"))).UseSource(m)
+	if s := query(t, q); s != "d paide paid" || c.Gets() != gets {
+		t.Errorf("found %q through the Mirror with %d fetches", s, c.Gets()-gets)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// GetRate returns the Entry Credit exchange rate from factomd as the number of
+// factoshis per Entry Credit.
+func GetRate() (Factoshi, error) {
+	resp, err := http.Get(
+		fmt.Sprintf("http://%s/v1/factoid-get-fee/", server))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != 200 {
+		return 0, fmt.Errorf(string(body))
+	}
+
+	type rate struct {
+		Fee int64
+	}
+	r := new(rate)
+	if err := json.Unmarshal(body, r); err != nil {
+		return 0, fmt.Errorf("%s: %s\n", err, body)
+	}
+
+	return Factoshi(r.Fee), nil
+}
+
+// GetWalletRate returns the Entry Credit exchange rate known to fctwallet as
+// the number of factoshis per Entry Credit.
+func GetWalletRate() (Factoshi, error) {
+	resp, err := http.Get(
+		fmt.Sprintf("http://%s/v1/factoid-get-fee/", serverFct))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	type x struct {
+		Response string
+		Success  bool
+	}
+	b := new(x)
+	if err := json.Unmarshal(body, b); err != nil {
+		return 0, fmt.Errorf("%s: %s\n", err, body)
+	}
+
+	if !b.Success {
+		return 0, errors.New(b.Response)
+	}
+
+	return ParseFactoshi(b.Response)
+}
+
+// ECQuote is the cost of buying the Entry Credits needed to pay for a set of
+// Entries and Chains.
+type ECQuote struct {
+	EntryCredits int64
+	Rate         Factoshi
+
+	// Amount is the factoshis converted to Entry Credits and Fee is the
+	// transaction fee for the purchase. Total is their sum.
+	Amount Factoshi
+	Fee    Factoshi
+	Total  Factoshi
+}
+
+// QuoteEntries returns the cost of the Entry Credits to commit the Entries at
+// the exchange rate.
+func QuoteEntries(rate Factoshi, es ...*Entry) (*ECQuote, error) {
+	var n int64
+	for _, e := range es {
+		c, err := entryCost(e)
+		if err != nil {
+			return nil, err
+		}
+		n += int64(c)
+	}
+	return QuoteEntryCredits(rate, n)
+}
+
+// QuoteChains returns the cost of the Entry Credits to commit the Chains at
+// the exchange rate. Each Chain costs 10 Entry Credits more than its First
+// Entry.
+func QuoteChains(rate Factoshi, cs ...*Chain) (*ECQuote, error) {
+	var n int64
+	for _, c := range cs {
+		d, err := entryCost(c.FirstEntry)
+		if err != nil {
+			return nil, err
+		}
+		n += int64(d) + 10
+	}
+	return QuoteEntryCredits(rate, n)
+}
+
+// QuoteEntryCredits returns the cost of buying ec Entry Credits at the
+// exchange rate with a single input and EC output transaction.
+func QuoteEntryCredits(rate Factoshi, ec int64) (*ECQuote, error) {
+	q := new(ECQuote)
+	q.EntryCredits = ec
+	q.Rate = rate
+
+	var err error
+	if q.Amount, err = FactoshiFromEC(ec, rate); err != nil {
+		return nil, err
+	}
+
+	t := NewTransaction()
+	t.AddInput(new([32]byte), q.Amount)
+	t.ECOutputs = append(t.ECOutputs, &TransAddress{q.Amount, make([]byte, 32)})
+	if q.Fee, err = t.CalculateFee(rate); err != nil {
+		return nil, err
+	}
+
+	if q.Total, err = q.Amount.Add(q.Fee); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (q *ECQuote) String() string {
+	var s string
+	s += fmt.Sprintln("EntryCredits:", q.EntryCredits)
+	s += fmt.Sprintln("Rate:", q.Rate)
+	s += fmt.Sprintln("Amount:", q.Amount)
+	s += fmt.Sprintln("Fee:", q.Fee)
+	s += fmt.Sprintln("Total:", q.Total)
+	return s
+}
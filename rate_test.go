@@ -0,0 +1,43 @@
+package factom_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestQuoteEntries(t *testing.T) {
+	e := factom.NewEntry()
+	if err := e.UnmarshalJSON(jsonentry); err != nil {
+		t.Error(err)
+	}
+	c := factom.NewChain(e)
+
+	q, err := factom.QuoteEntries(1000, e, e)
+	if err != nil {
+		t.Error(err)
+	}
+	if q.EntryCredits != 2 || q.Amount != 2000 || q.Fee != 12000 {
+		t.Errorf("wrong quote\n%s", q)
+	}
+
+	q, err = factom.QuoteChains(1000, c)
+	if err != nil {
+		t.Error(err)
+	}
+	if q.EntryCredits != 11 || q.Total != 23000 {
+		t.Errorf("wrong quote\n%s", q)
+	}
+	t.Log(q)
+}
+
+func TestGetWalletRateFailure(t *testing.T) {
+	newTestServer(t, map[string]http.HandlerFunc{"/v1/": func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Response":"100% broken","Success":false}`)
+	}})
+	if _, err := factom.GetWalletRate(); err == nil || err.Error() != "100% broken" {
+		t.Errorf("wrong error %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"fmt"
+	"time"
+)
+
+// EntryRecord is an Entry with where and when it was recorded.
+type EntryRecord struct {
+	Entry     *Entry
+	EntryHash string
+
+	// EBlockKeyMR and EBlockSequence are the Entry Block holding the Entry,
+	// and Index is the position of the Entry in it.
+	EBlockKeyMR    string
+	EBlockSequence int
+	Index          int
+
+	// Minute is the minute of the Directory Block, 0 to 9, in which the Entry
+	// was recorded. DBHeight is the height of the Directory Block, or -1 if
+	// it could not be found.
+	Minute   int
+	DBHeight int
+
+	Timestamp time.Time
+
+	// Pending is set for an Entry revealed but not yet in a Directory
+	// Block. It has no Entry Block, Index or Timestamp, and DBHeight is -1.
+	Pending bool
+}
+
+// dbHeighter is a Source that can find the Directory Block height of an Entry
+// Block.
+type dbHeighter interface {
+	EBlockDBHeight(keymr string) (int, bool)
+}
+
+func (network) EBlockDBHeight(keymr string) (int, bool) {
+	h, err := GetEBlockDBHeight(keymr)
+	return h, err == nil
+}
+
+// newEntryRecord returns the record of the Entry at index i of the Entry Block.
+func newEntryRecord(e *Entry, keymr string, eb *EBlock, i, dbheight int) *EntryRecord {
+	v := eb.EntryList[i]
+
+	r := new(EntryRecord)
+	r.Entry = e
+	r.EntryHash = v.EntryHash
+	r.EBlockKeyMR = keymr
+	r.EBlockSequence = eb.Header.BlockSequenceNumber
+	r.Index = i
+	r.Minute = entryMinute(eb, v)
+	r.DBHeight = dbheight
+	r.Timestamp = v.Time()
+
+	return r
+}
+
+// newPendingRecord returns the record of the pending Entry.
+func newPendingRecord(e *Entry, p *PendingEntry) *EntryRecord {
+	r := new(EntryRecord)
+	r.Entry = e
+	r.EntryHash = p.EntryHash
+	r.EBlockSequence = -1
+	r.Index = -1
+	r.DBHeight = -1
+	r.Pending = true
+
+	return r
+}
+
+// entryMinute returns the minute of the Entry from its time after the start of
+// its Entry Block.
+func entryMinute(eb *EBlock, v EBEntry) int {
+	m := int(v.Timestamp-int64(eb.Header.Timestamp)) / 60
+	if m < 0 {
+		return 0
+	}
+	if m > 9 {
+		return 9
+	}
+	return m
+}
+
+func (r *EntryRecord) String() string {
+	var s string
+	s += fmt.Sprintln("EntryHash:", r.EntryHash)
+	s += fmt.Sprintln("EBlockKeyMR:", r.EBlockKeyMR)
+	s += fmt.Sprintln("EBlockSequence:", r.EBlockSequence)
+	s += fmt.Sprintln("Index:", r.Index)
+	s += fmt.Sprintln("Minute:", r.Minute)
+	s += fmt.Sprintln("DBHeight:", r.DBHeight)
+	s += fmt.Sprintln("Timestamp:", r.Timestamp)
+	s += fmt.Sprintln("Pending:", r.Pending)
+	return s
+}
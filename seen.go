@@ -0,0 +1,101 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+)
+
+// SeenSet is a set of Entry hashes known to be written. A Writer with a Seen
+// set skips the Entries in it without asking the network, which keeps bulk
+// jobs that are rerun from paying for or looking up every Entry again.
+type SeenSet interface {
+	Seen(hash string) bool
+	Add(hash string) error
+}
+
+// MemorySeenSet is a SeenSet that lasts as long as the process.
+type MemorySeenSet struct {
+	mu     sync.RWMutex
+	hashes map[string]bool
+}
+
+func NewMemorySeenSet() *MemorySeenSet {
+	s := new(MemorySeenSet)
+	s.hashes = make(map[string]bool)
+
+	return s
+}
+
+func (s *MemorySeenSet) Seen(hash string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hashes[hash]
+}
+
+func (s *MemorySeenSet) Add(hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hashes[hash] = true
+	return nil
+}
+
+// FileSeenSet is a SeenSet kept in a file of one Entry hash per line so that it
+// lasts between runs.
+type FileSeenSet struct {
+	mem *MemorySeenSet
+	mu  sync.Mutex
+	f   *os.File
+}
+
+// OpenSeenFile opens or creates the SeenSet file at path and loads the hashes
+// already in it.
+func OpenSeenFile(path string) (*FileSeenSet, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileSeenSet{mem: NewMemorySeenSet(), f: f}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if h := strings.TrimSpace(sc.Text()); h != "" {
+			s.mem.Add(h)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *FileSeenSet) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+func (s *FileSeenSet) Seen(hash string) bool {
+	return s.mem.Seen(hash)
+}
+
+func (s *FileSeenSet) Add(hash string) error {
+	if s.mem.Seen(hash) {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.f.WriteString(hash + "\n"); err != nil {
+		return err
+	}
+	return s.mem.Add(hash)
+}
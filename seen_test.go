@@ -0,0 +1,32 @@
+package factom_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestFileSeenSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seen")
+
+	s, err := factom.OpenSeenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range []string{"aa", "bb", "aa"} {
+		if err := s.Add(h); err != nil {
+			t.Error(err)
+		}
+	}
+	s.Close()
+
+	s, err = factom.OpenSeenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if !s.Seen("aa") || !s.Seen("bb") || s.Seen("cc") {
+		t.Error("Seen set was not reloaded")
+	}
+}
@@ -0,0 +1,51 @@
+package factom_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+// useServer points the factomd server, and the fctwallet server if wallet is
+// set, at the test server url until the test ends.
+func useServer(t *testing.T, url string, wallet bool) {
+	server, fct := factom.Server(), factom.Wallet()
+	t.Cleanup(func() {
+		factom.SetServer(server)
+		factom.SetWallet(fct)
+	})
+
+	factom.SetServer(strings.TrimPrefix(url, "http://"))
+	if wallet {
+		factom.SetWallet(strings.TrimPrefix(url, "http://"))
+	}
+}
+
+// newTestServer serves each request with the handler of the longest matching
+// path prefix, where a nil handler answers with an empty success, and any
+// other path with 404 Not Found. It is both the factomd and the fctwallet
+// server until the test ends.
+func newTestServer(t *testing.T, routes map[string]http.HandlerFunc) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		match := ""
+		for prefix := range routes {
+			if strings.HasPrefix(r.URL.Path, prefix) && len(prefix) > len(match) {
+				match = prefix
+			}
+		}
+		if match == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if h := routes[match]; h != nil {
+			h(w, r)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	useServer(t, ts.URL, true)
+	return ts
+}
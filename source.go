@@ -0,0 +1,30 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+// Source is where Chain data is read from. Network reads from factomd, and a
+// Mirror reads from its local copy.
+type Source interface {
+	GetChainHead(chainid string) (*ChainHead, error)
+	GetEBlock(keymr string) (*EBlock, error)
+	GetEntry(hash string) (*Entry, error)
+}
+
+// Network is the Source that reads from the factomd server set by SetServer.
+var Network Source = network{}
+
+type network struct{}
+
+func (network) GetChainHead(chainid string) (*ChainHead, error) {
+	return GetChainHead(chainid)
+}
+
+func (network) GetEBlock(keymr string) (*EBlock, error) {
+	return GetEBlock(keymr)
+}
+
+func (network) GetEntry(hash string) (*Entry, error) {
+	return GetEntry(hash)
+}
@@ -0,0 +1,199 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AckStatus is how far a commit or an Entry has progressed into the network.
+type AckStatus int
+
+const (
+	// AckUnknown means the network has not seen the hash.
+	AckUnknown AckStatus = iota
+
+	// AckNotConfirmed means the network has seen the hash but the leader
+	// has not acknowledged it.
+	AckNotConfirmed
+
+	// AckTransactionACK means the leader has acknowledged the hash and it
+	// will be included in the next Directory Block.
+	AckTransactionACK
+
+	// AckDBlockConfirmed means the hash is recorded in a Directory Block.
+	AckDBlockConfirmed
+)
+
+var ackStatusNames = map[AckStatus]string{
+	AckUnknown:         "Unknown",
+	AckNotConfirmed:    "NotConfirmed",
+	AckTransactionACK:  "TransactionACK",
+	AckDBlockConfirmed: "DBlockConfirmed",
+}
+
+func (s AckStatus) String() string {
+	if n, ok := ackStatusNames[s]; ok {
+		return n
+	}
+	return fmt.Sprintf("AckStatus(%d)", int(s))
+}
+
+// ParseAckStatus returns the AckStatus for the factomd status name. An empty
+// name is AckUnknown.
+func ParseAckStatus(name string) (AckStatus, error) {
+	if name == "" {
+		return AckUnknown, nil
+	}
+	for s, n := range ackStatusNames {
+		if n == name {
+			return s, nil
+		}
+	}
+	return AckUnknown, fmt.Errorf("Unknown status %s", name)
+}
+
+// EntryStatus is the progress of an Entry and of the commit that paid for it.
+type EntryStatus struct {
+	CommitTxID   string
+	EntryHash    string
+	CommitStatus AckStatus
+	EntryStatus  AckStatus
+}
+
+// EntryAck returns the status of the Entry with the hash in the Chain.
+func EntryAck(hash, chainid string) (*EntryStatus, error) {
+	return ack(hash, chainid)
+}
+
+// CommitAck returns the status of the Entry or Chain commit with the TxID.
+func CommitAck(txid string) (*EntryStatus, error) {
+	// the "c" chainid asks factomd to treat the hash as a commit TxID
+	return ack(txid, "c")
+}
+
+// GetStatus returns the status of the hash, which may be either a commit TxID
+// or an Entry hash, and the AckStatus of that commit or Entry. The Chain of an
+// Entry hash is found from the Entry, or from the pending Entries if it is not
+// yet in a Directory Block; an Entry in neither is AckUnknown.
+func GetStatus(hash string) (*EntryStatus, AckStatus, error) {
+	s, err := CommitAck(hash)
+	if err != nil {
+		return nil, AckUnknown, err
+	}
+	if s.CommitStatus != AckUnknown {
+		return s, s.CommitStatus, nil
+	}
+
+	chainid := ""
+	if e, err := GetEntry(hash); err == nil {
+		chainid = e.ChainID
+	} else {
+		p, err := GetPendingEntry(hash)
+		if err != nil {
+			return nil, AckUnknown, err
+		}
+		if p != nil {
+			chainid = p.ChainID
+		}
+	}
+	if chainid == "" {
+		return &EntryStatus{EntryHash: hash}, AckUnknown, nil
+	}
+
+	if s, err = EntryAck(hash, chainid); err != nil {
+		return nil, AckUnknown, err
+	}
+	return s, s.EntryStatus, nil
+}
+
+// WaitFor polls the status of the commit TxID or Entry hash until it reaches
+// the level, and returns the last status seen. Polling starts quickly, for the
+// acknowledgement, and backs off towards the Directory Block time. An error
+// answered by factomd is returned at once, while other errors, such as an
+// unreachable server, are retried. WaitFor returns the context error, wrapping
+// the last error if the last poll failed, if the context is done first.
+func WaitFor(ctx context.Context, hash string, level AckStatus) (*EntryStatus, error) {
+	const (
+		minWait = 500 * time.Millisecond
+		maxWait = 15 * time.Second
+	)
+
+	var last *EntryStatus
+	var lastErr error
+	for wait := minWait; ; {
+		s, st, err := GetStatus(hash)
+		if err != nil {
+			// factomd refusing the request will not change by waiting
+			var jerr *JSONError
+			if errors.As(err, &jerr) {
+				return last, err
+			}
+		} else {
+			last = s
+			if st >= level {
+				return s, nil
+			}
+		}
+		lastErr = err
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			if lastErr != nil {
+				return last, fmt.Errorf("%w: %v", ctx.Err(), lastErr)
+			}
+			return last, ctx.Err()
+		case <-t.C:
+		}
+
+		if wait *= 2; wait > maxWait {
+			wait = maxWait
+		}
+	}
+}
+
+func ack(hash, chainid string) (*EntryStatus, error) {
+	type params struct {
+		Hash    string `json:"hash"`
+		ChainID string `json:"chainid,omitempty"`
+	}
+	type txdata struct {
+		Status string `json:"status"`
+	}
+	type result struct {
+		CommitTxID string `json:"committxid"`
+		EntryHash  string `json:"entryhash"`
+		CommitData txdata `json:"commitdata"`
+		EntryData  txdata `json:"entrydata"`
+	}
+
+	r := new(result)
+	if err := factomdRequest("ack", &params{hash, chainid}, r); err != nil {
+		return nil, err
+	}
+
+	// a status this library does not know is AckUnknown
+	s := new(EntryStatus)
+	s.CommitTxID = r.CommitTxID
+	s.EntryHash = r.EntryHash
+	s.CommitStatus, _ = ParseAckStatus(r.CommitData.Status)
+	s.EntryStatus, _ = ParseAckStatus(r.EntryData.Status)
+
+	return s, nil
+}
+
+func (s *EntryStatus) String() string {
+	var str string
+	str += fmt.Sprintln("CommitTxID:", s.CommitTxID)
+	str += fmt.Sprintln("EntryHash:", s.EntryHash)
+	str += fmt.Sprintln("CommitStatus:", s.CommitStatus)
+	str += fmt.Sprintln("EntryStatus:", s.EntryStatus)
+	return str
+}
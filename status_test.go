@@ -0,0 +1,150 @@
+package factom_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+func TestWaitFor(t *testing.T) {
+	polls := 0
+	newTestServer(t, map[string]http.HandlerFunc{"/v2": func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string
+			Params struct{ Hash, ChainID string }
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method != "ack" || req.Params.ChainID != "c" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+
+		polls++
+		status := "TransactionACK"
+		if polls == 1 {
+			status = "NotConfirmed"
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":{
+			"committxid":"` + req.Params.Hash + `",
+			"commitdata":{"status":"` + status + `"},
+			"entrydata":{"status":"Unknown"}}}`))
+	}})
+
+	s, err := factom.WaitFor(context.Background(), factom.ZeroHash, factom.AckTransactionACK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polls != 2 || s.CommitStatus != factom.AckTransactionACK {
+		t.Errorf("wrong status after %d polls\n%s", polls, s)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := factom.WaitFor(ctx, factom.ZeroHash, factom.AckDBlockConfirmed); err != context.DeadlineExceeded {
+		t.Errorf("WaitFor did not time out: %v", err)
+	}
+}
+
+func TestWaitForErrors(t *testing.T) {
+	// an error answered by factomd is returned at once
+	polls := 0
+	newTestServer(t, map[string]http.HandlerFunc{"/v2": func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		w.Write([]byte(`{"jsonrpc":"2.0","id":0,"error":{"code":-32602,"message":"Invalid params"}}`))
+	}})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, err := factom.WaitFor(ctx, "bad", factom.AckTransactionACK)
+	var jerr *factom.JSONError
+	if !errors.As(err, &jerr) || polls != 1 {
+		t.Errorf("wrong error after %d polls: %v", polls, err)
+	}
+
+	// an unreachable server is retried and reported with the timeout
+	ts := newTestServer(t, nil)
+	ts.Close()
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = factom.WaitFor(ctx, factom.ZeroHash, factom.AckTransactionACK)
+	if !errors.Is(err, context.DeadlineExceeded) || err == context.DeadlineExceeded {
+		t.Errorf("timeout does not wrap the last error: %v", err)
+	}
+}
+
+func TestGetStatusEntry(t *testing.T) {
+	const chainid = "ab"
+	newTestServer(t, map[string]http.HandlerFunc{
+		"/v1/entry-by-hash/": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "entry not found", http.StatusBadRequest)
+		},
+		"/v2": func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				Method string
+				Params struct{ Hash, ChainID string }
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			switch {
+			case req.Method == "pending-entries":
+				w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":[
+					{"entryhash":"` + factom.ZeroHash + `","chainid":"` + chainid + `","status":"TransactionACK"}]}`))
+			case req.Method == "ack" && req.Params.ChainID == "c":
+				w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":{"commitdata":{"status":"Unknown"}}}`))
+			case req.Method == "ack" && req.Params.ChainID == chainid:
+				w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":{
+					"entryhash":"` + req.Params.Hash + `",
+					"commitdata":{"status":"Unknown"},
+					"entrydata":{"status":"TransactionACK"}}}`))
+			default:
+				http.Error(w, "unexpected request", http.StatusBadRequest)
+			}
+		},
+	})
+
+	s, st, err := factom.GetStatus(factom.ZeroHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st != factom.AckTransactionACK || s.EntryHash != factom.ZeroHash {
+		t.Errorf("wrong status %s\n%s", st, s)
+	}
+
+	if _, st, err := factom.GetStatus("1234"); err != nil || st != factom.AckUnknown {
+		t.Errorf("wrong status of an unknown hash %s %v", st, err)
+	}
+}
+
+func TestAckUnknownStatus(t *testing.T) {
+	newTestServer(t, map[string]http.HandlerFunc{"/v2": func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method == "pending-entries" {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":[]}`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":{
+			"commitdata":{"status":"Mystery"},
+			"entrydata":{"status":"Mystery"}}}`))
+	}})
+
+	s, err := factom.CommitAck(factom.ZeroHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.CommitStatus != factom.AckUnknown || s.EntryStatus != factom.AckUnknown {
+		t.Errorf("wrong status\n%s", s)
+	}
+
+	// polling goes on without an error until the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := factom.WaitFor(ctx, factom.ZeroHash, factom.AckTransactionACK); err != context.DeadlineExceeded {
+		t.Errorf("WaitFor failed polling an unknown status: %v", err)
+	}
+}
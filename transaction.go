@@ -0,0 +1,394 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"time"
+
+	ed "github.com/FactomProject/ed25519"
+)
+
+const (
+	// TransactionVersion is the only Factoid Transaction version
+	TransactionVersion = 2
+
+	// rcdType1 is the Redeem Condition Datastructure for a single ed25519 key
+	rcdType1 = 1
+)
+
+// Transaction is a Factoid Transaction moving factoshis from one or more
+// input addresses to Factoid outputs and Entry Credit outputs.
+type Transaction struct {
+	MilliTimestamp int64
+	Inputs         []*TransAddress
+	Outputs        []*TransAddress
+	ECOutputs      []*TransAddress
+	RCDs           []*RCD
+}
+
+// TransAddress is an amount of factoshis paid from or to a 32 byte address.
+// For Inputs and Outputs the address is the RCD hash; for ECOutputs it is the
+// Entry Credit public key.
+type TransAddress struct {
+	Amount  Factoshi
+	Address []byte
+}
+
+// RCD is the type 1 Redeem Condition Datastructure revealed for an input along
+// with the signature over the transaction.
+type RCD struct {
+	PubKey    []byte
+	Signature []byte
+}
+
+func NewTransaction() *Transaction {
+	t := new(Transaction)
+	t.MilliTimestamp = time.Now().UnixNano() / 1e6
+
+	return t
+}
+
+// AddInput adds an input paid by the Factoid address of the public key, and
+// attaches the unsigned RCD for the key.
+func (t *Transaction) AddInput(pub *[32]byte, amount Factoshi) {
+	r := &RCD{PubKey: append([]byte{}, pub[:]...)}
+	t.Inputs = append(t.Inputs, &TransAddress{amount, r.Hash()})
+	t.RCDs = append(t.RCDs, r)
+}
+
+// AddOutput adds a Factoid output to the human readable address.
+func (t *Transaction) AddOutput(addr string, amount Factoshi) error {
+	p, err := ParseFctAddress(addr)
+	if err != nil {
+		return err
+	}
+	t.Outputs = append(t.Outputs, &TransAddress{amount, p})
+	return nil
+}
+
+// AddECOutput adds an Entry Credit purchase to the human readable address.
+func (t *Transaction) AddECOutput(addr string, amount Factoshi) error {
+	p, err := ParseECAddress(addr)
+	if err != nil {
+		return err
+	}
+	t.ECOutputs = append(t.ECOutputs, &TransAddress{amount, p})
+	return nil
+}
+
+// CalculateFee returns the fee in factoshis for the Transaction at the Entry
+// Credit exchange rate. The fee is 1 EC per KB of the signed Transaction, 10 EC
+// per Factoid or EC output, and 1 EC per signature.
+func (t *Transaction) CalculateFee(rate Factoshi) (Factoshi, error) {
+	p, err := t.MarshalLedgerBinary()
+	if err != nil {
+		return 0, err
+	}
+
+	// every input is paid for as though it is signed
+	l := len(p) + len(t.Inputs)*(1+32+64)
+
+	n := int64(l / 1024)
+	if l%1024 > 0 {
+		n += 1
+	}
+	n += 10 * int64(len(t.Outputs)+len(t.ECOutputs))
+	n += int64(len(t.Inputs))
+
+	return FactoshiFromEC(n, rate)
+}
+
+// AddFee adds the fee for the Transaction to the input from the public key.
+func (t *Transaction) AddFee(pub *[32]byte, rate Factoshi) error {
+	r := &RCD{PubKey: pub[:]}
+	for _, in := range t.Inputs {
+		if bytes.Equal(in.Address, r.Hash()) {
+			fee, err := t.CalculateFee(rate)
+			if err != nil {
+				return err
+			}
+			in.Amount, err = in.Amount.Add(fee)
+			return err
+		}
+	}
+	return fmt.Errorf("%s is not an input to the transaction",
+		FctAddressString(r.Hash()))
+}
+
+// Fee returns the sum of the inputs less the sum of the outputs, or
+// ErrFactoshiOverflow.
+func (t *Transaction) Fee() (Factoshi, error) {
+	var f Factoshi
+	var err error
+	for _, v := range t.Inputs {
+		if f, err = f.Add(v.Amount); err != nil {
+			return 0, err
+		}
+	}
+	for _, list := range [][]*TransAddress{t.Outputs, t.ECOutputs} {
+		for _, v := range list {
+			if f, err = f.Sub(v.Amount); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return f, nil
+}
+
+// Sign signs every input of the Transaction redeemed by the private key.
+func (t *Transaction) Sign(pri *[64]byte) error {
+	p, err := t.MarshalLedgerBinary()
+	if err != nil {
+		return err
+	}
+
+	signed := false
+	for _, r := range t.RCDs {
+		if bytes.Equal(r.PubKey, pri[32:]) {
+			sig := ed.Sign(pri, p)
+			r.Signature = sig[:]
+			signed = true
+		}
+	}
+	if !signed {
+		return fmt.Errorf("The key does not redeem any transaction input")
+	}
+	return nil
+}
+
+// Verify checks that every input has a matching RCD and a valid signature.
+func (t *Transaction) Verify() error {
+	if len(t.RCDs) != len(t.Inputs) {
+		return fmt.Errorf("Transaction has %d inputs and %d RCDs",
+			len(t.Inputs), len(t.RCDs))
+	}
+
+	p, err := t.MarshalLedgerBinary()
+	if err != nil {
+		return err
+	}
+
+	for i, r := range t.RCDs {
+		if !bytes.Equal(t.Inputs[i].Address, r.Hash()) {
+			return fmt.Errorf("RCD %d does not match input address", i)
+		}
+		if len(r.Signature) != 64 {
+			return fmt.Errorf("Input %d is not signed", i)
+		}
+		if !verifySig(r.PubKey, p, r.Signature) {
+			return fmt.Errorf("Invalid signature on input %d", i)
+		}
+	}
+	return nil
+}
+
+// TxID returns the hex encoded sha256 hash of the ledger portion of the
+// Transaction.
+func (t *Transaction) TxID() (string, error) {
+	p, err := t.MarshalLedgerBinary()
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(p)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// MarshalLedgerBinary marshals the part of the Transaction that is signed and
+// hashed for the TxID; everything except the RCDs and signatures.
+func (t *Transaction) MarshalLedgerBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	if len(t.Inputs) > 255 || len(t.Outputs) > 255 || len(t.ECOutputs) > 255 {
+		return nil, fmt.Errorf("Transaction cannot have more than 255 inputs or outputs")
+	}
+
+	// varint Version
+	writeVarInt(buf, TransactionVersion)
+
+	// 6 byte milliTimestamp
+	buf.Write(milliBytes(t.MilliTimestamp))
+
+	// 1 byte counts of inputs, outputs, and ec outputs
+	buf.WriteByte(byte(len(t.Inputs)))
+	buf.WriteByte(byte(len(t.Outputs)))
+	buf.WriteByte(byte(len(t.ECOutputs)))
+
+	for _, list := range [][]*TransAddress{t.Inputs, t.Outputs, t.ECOutputs} {
+		for _, v := range list {
+			if len(v.Address) != 32 {
+				return nil, fmt.Errorf("Invalid address length %d", len(v.Address))
+			}
+			if v.Amount < 0 {
+				return nil, fmt.Errorf("Negative amount %s", v.Amount)
+			}
+			// varint amount
+			writeVarInt(buf, uint64(v.Amount))
+			// 32 byte address
+			buf.Write(v.Address)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (t *Transaction) MarshalBinary() ([]byte, error) {
+	p, err := t.MarshalLedgerBinary()
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(p)
+
+	for _, r := range t.RCDs {
+		buf.Write(r.MarshalBinary())
+		if len(r.Signature) == 64 {
+			buf.Write(r.Signature)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary reads a Transaction as written by MarshalBinary, which leaves
+// out the signatures of inputs not yet signed.
+func (t *Transaction) UnmarshalBinary(data []byte) error {
+	buf := bytes.NewBuffer(data)
+	if err := t.unmarshalLedger(buf); err != nil {
+		return err
+	}
+	ledger := data[:len(data)-buf.Len()]
+
+	// the number of signatures follows from the length left after the RCDs
+	sigs := 0
+	if n := buf.Len(); n > 0 {
+		extra := n - len(t.Inputs)*(1+32)
+		if extra < 0 || extra%64 != 0 || extra/64 > len(t.Inputs) {
+			return fmt.Errorf("Invalid length %d of RCDs and signatures for %d inputs",
+				n, len(t.Inputs))
+		}
+		sigs = extra / 64
+	}
+	return t.unmarshalRCDs(buf, ledger, sigs)
+}
+
+// unmarshal reads one signed Transaction from buf, leaving any following data.
+func (t *Transaction) unmarshal(buf *bytes.Buffer) error {
+	if err := t.unmarshalLedger(buf); err != nil {
+		return err
+	}
+	return t.unmarshalRCDs(buf, nil, len(t.Inputs))
+}
+
+// unmarshalLedger reads the part of a Transaction before the RCDs.
+func (t *Transaction) unmarshalLedger(buf *bytes.Buffer) error {
+	v, err := readVarInt(buf)
+	if err != nil {
+		return err
+	}
+	if v != TransactionVersion {
+		return fmt.Errorf("Unknown Transaction version %d", v)
+	}
+
+	if buf.Len() < 9 {
+		return fmt.Errorf("Transaction header is too short")
+	}
+	t.MilliTimestamp = readMilli(buf)
+
+	counts := buf.Next(3)
+	t.Inputs = make([]*TransAddress, counts[0])
+	t.Outputs = make([]*TransAddress, counts[1])
+	t.ECOutputs = make([]*TransAddress, counts[2])
+
+	for _, list := range [][]*TransAddress{t.Inputs, t.Outputs, t.ECOutputs} {
+		for i := range list {
+			a := new(TransAddress)
+			amt, err := readVarInt(buf)
+			if err != nil {
+				return err
+			}
+			if amt > math.MaxInt64 {
+				return ErrFactoshiOverflow
+			}
+			a.Amount = Factoshi(amt)
+			if buf.Len() < 32 {
+				return fmt.Errorf("Transaction address is too short")
+			}
+			a.Address = append([]byte{}, buf.Next(32)...)
+			list[i] = a
+		}
+	}
+
+	return nil
+}
+
+// unmarshalRCDs reads the RCD of each input, and sigs signatures. When some
+// but not all inputs are signed, an RCD is followed by a signature only if it
+// verifies against the ledger.
+func (t *Transaction) unmarshalRCDs(buf *bytes.Buffer, ledger []byte, sigs int) error {
+	// an unsigned transaction may end after the ledger
+	t.RCDs = nil
+	for i := 0; i < len(t.Inputs) && buf.Len() > 0; i++ {
+		if buf.Len() < 1+32 {
+			return fmt.Errorf("RCD %d is too short", i)
+		}
+		if b, _ := buf.ReadByte(); b != rcdType1 {
+			return fmt.Errorf("Unknown RCD type %d", b)
+		}
+		r := new(RCD)
+		r.PubKey = append([]byte{}, buf.Next(32)...)
+
+		if left := len(t.Inputs) - i; sigs == left ||
+			(sigs > 0 && buf.Len() >= 64 && verifySig(r.PubKey, ledger, buf.Bytes()[:64])) {
+			if buf.Len() < 64 {
+				return fmt.Errorf("Signature %d is too short", i)
+			}
+			r.Signature = append([]byte{}, buf.Next(64)...)
+			sigs--
+		}
+		t.RCDs = append(t.RCDs, r)
+	}
+
+	return nil
+}
+
+func (t *Transaction) String() string {
+	var s string
+	if id, err := t.TxID(); err == nil {
+		s += fmt.Sprintln("TxID:", id)
+	}
+	s += fmt.Sprintln("Timestamp:", time.Unix(0, t.MilliTimestamp*1e6).UTC())
+	for _, v := range t.Inputs {
+		s += fmt.Sprintln("Input:", FctAddressString(v.Address), v.Amount)
+	}
+	for _, v := range t.Outputs {
+		s += fmt.Sprintln("Output:", FctAddressString(v.Address), v.Amount)
+	}
+	for _, v := range t.ECOutputs {
+		s += fmt.Sprintln("ECOutput:", ECAddressString(v.Address), v.Amount)
+	}
+	if f, err := t.Fee(); err == nil {
+		s += fmt.Sprintln("Fee:", f)
+	}
+	for _, r := range t.RCDs {
+		s += fmt.Sprintln("RCD {")
+		s += fmt.Sprintln("	PubKey", hex.EncodeToString(r.PubKey))
+		s += fmt.Sprintln("	Signature", hex.EncodeToString(r.Signature))
+		s += fmt.Sprintln("}")
+	}
+	return s
+}
+
+// Hash returns the Factoid address redeemed by the RCD; shad(RCD).
+func (r *RCD) Hash() []byte {
+	return shad(r.MarshalBinary())
+}
+
+func (r *RCD) MarshalBinary() []byte {
+	return append([]byte{rcdType1}, r.PubKey...)
+}
@@ -0,0 +1,154 @@
+package factom_test
+
+import (
+	"crypto/rand"
+	"math"
+	"testing"
+
+	ed "github.com/FactomProject/ed25519"
+	"github.com/FactomProject/factom"
+)
+
+func TestAddressString(t *testing.T) {
+	// Factoid address of the zero RCD hash
+	zero := make([]byte, 32)
+	s := factom.FctAddressString(zero)
+	t.Log(s)
+
+	p, err := factom.ParseFctAddress(s)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(p) != string(zero) {
+		t.Errorf("address did not round trip: %x", p)
+	}
+
+	if _, err := factom.ParseECAddress(s); err == nil {
+		t.Errorf("Factoid address %s parsed as an EC address", s)
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	pub, pri, err := ed.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := factom.FctAddressString(make([]byte, 32))
+	ec := factom.ECAddressString(make([]byte, 32))
+
+	tx := factom.NewTransaction()
+	tx.AddInput(pub, 3e8)
+	if err := tx.AddOutput(out, 2e8); err != nil {
+		t.Error(err)
+	}
+	if err := tx.AddECOutput(ec, 1e8); err != nil {
+		t.Error(err)
+	}
+
+	// 1 EC for the size, 20 EC for 2 outputs, 1 EC for 1 signature
+	fee, err := tx.CalculateFee(1000)
+	if err != nil {
+		t.Error(err)
+	}
+	if fee != 22000 {
+		t.Errorf("wrong fee %d", fee)
+	}
+	if err := tx.AddFee(pub, 1000); err != nil {
+		t.Error(err)
+	}
+	if f, err := tx.Fee(); err != nil || f != 22000 {
+		t.Errorf("wrong fee %d %v", f, err)
+	}
+
+	// an unsigned transaction leaves out the signatures
+	unsigned := roundTrip(t, tx)
+	if len(unsigned.RCDs) != 1 || unsigned.RCDs[0].Signature != nil {
+		t.Errorf("wrong RCDs of an unsigned transaction\n%s", unsigned)
+	}
+
+	if err := tx.Verify(); err == nil {
+		t.Error("unsigned transaction was verified")
+	}
+	if err := tx.Sign(pri); err != nil {
+		t.Error(err)
+	}
+	if err := tx.Verify(); err != nil {
+		t.Error(err)
+	}
+
+	tx2 := roundTrip(t, tx)
+	if err := tx2.Verify(); err != nil {
+		t.Error(err)
+	}
+
+	id1, _ := tx.TxID()
+	id2, _ := tx2.TxID()
+	if id1 != id2 {
+		t.Errorf("TxID changed from %s to %s", id1, id2)
+	}
+	t.Log(tx2)
+}
+
+func TestTransactionPartlySigned(t *testing.T) {
+	tx := factom.NewTransaction()
+	pris := make([]*[64]byte, 0)
+	for i := 0; i < 3; i++ {
+		pub, pri, err := ed.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx.AddInput(pub, factom.Factoshi(1e8*(i+1)))
+		pris = append(pris, pri)
+	}
+	if err := tx.AddOutput(factom.FctAddressString(make([]byte, 32)), 6e8); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, signed := range []int{0, 1, 2} {
+		if signed > 0 {
+			if err := tx.Sign(pris[signed]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tx2 := roundTrip(t, tx)
+		if len(tx2.RCDs) != 3 {
+			t.Fatalf("decoded %d RCDs", len(tx2.RCDs))
+		}
+		for i, r := range tx2.RCDs {
+			if (r.Signature != nil) != (i > 0 && i <= signed) {
+				t.Errorf("wrong signature on input %d with %d signed\n%s", i, signed, tx2)
+			}
+		}
+	}
+
+	if err := tx.Sign(pris[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := roundTrip(t, tx).Verify(); err != nil {
+		t.Error(err)
+	}
+
+	tx.Inputs[0].Amount = math.MaxInt64
+	if _, err := tx.Fee(); err != factom.ErrFactoshiOverflow {
+		t.Errorf("fee did not overflow: %v", err)
+	}
+}
+
+// roundTrip marshals and unmarshals the Transaction, keeping the TxID.
+func roundTrip(t *testing.T, tx *factom.Transaction) *factom.Transaction {
+	t.Helper()
+	p, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx2 := new(factom.Transaction)
+	if err := tx2.UnmarshalBinary(p); err != nil {
+		t.Fatal(err)
+	}
+	id1, _ := tx.TxID()
+	id2, _ := tx2.TxID()
+	if id1 != id2 {
+		t.Errorf("TxID changed from %s to %s", id1, id2)
+	}
+	return tx2
+}
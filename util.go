@@ -9,7 +9,8 @@ import (
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/binary"
-	"time"
+	"encoding/hex"
+	"fmt"
 )
 
 const (
@@ -36,13 +37,17 @@ func Server() string {
 	return server
 }
 
-// milliTime returns a 6 byte slice representing the unix time in milliseconds
-func milliTime() (r []byte) {
-	buf := new(bytes.Buffer)
-	t := time.Now().UnixNano()
-	m := t / 1e6
-	binary.Write(buf, binary.BigEndian, m)
-	return buf.Bytes()[2:]
+// milliBytes returns the 6 byte big endian form of a millisecond timestamp
+func milliBytes(m int64) []byte {
+	p := make([]byte, 8)
+	binary.BigEndian.PutUint64(p, uint64(m))
+	return p[2:]
+}
+
+// readMilli reads a 6 byte millisecond timestamp from buf
+func readMilli(buf *bytes.Buffer) int64 {
+	p := append([]byte{0, 0}, buf.Next(6)...)
+	return int64(binary.BigEndian.Uint64(p))
 }
 
 // shad Double Sha256 Hash; sha256(sha256(data))
@@ -52,9 +57,84 @@ func shad(data []byte) []byte {
 	return h2[:]
 }
 
+// merkleRoot returns the root of the Factom merkle tree of the hashes, where
+// a node with no right sibling is paired with itself, or 32 zero bytes for no
+// hashes.
+func merkleRoot(hashes [][]byte) []byte {
+	if len(hashes) == 0 {
+		return make([]byte, 32)
+	}
+	for len(hashes) > 1 {
+		next := make([][]byte, 0, (len(hashes)+1)/2)
+		for i := 0; i < len(hashes); i += 2 {
+			right := hashes[i]
+			if i+1 < len(hashes) {
+				right = hashes[i+1]
+			}
+			h := sha256.Sum256(append(append([]byte{}, hashes[i]...), right...))
+			next = append(next, h[:])
+		}
+		hashes = next
+	}
+	return hashes[0]
+}
+
 // sha52
 func sha52(data []byte) []byte {
 	h1 := sha512.Sum512(data)
 	h2 := sha256.Sum256(append(h1[:], data...))
 	return h2[:]
 }
+
+// writeVarInt writes v to buf as a Factom variable length integer; 7 bits per
+// byte, most significant group first, with the high bit set on every byte but
+// the last.
+func writeVarInt(buf *bytes.Buffer, v uint64) {
+	p := []byte{byte(v & 0x7f)}
+	for v >>= 7; v > 0; v >>= 7 {
+		p = append([]byte{byte(v&0x7f) | 0x80}, p...)
+	}
+	buf.Write(p)
+}
+
+// readVarInt reads a Factom variable length integer from buf
+func readVarInt(buf *bytes.Buffer) (uint64, error) {
+	var v uint64
+	for i := 0; i < 10; i++ {
+		b, err := buf.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		v = v<<7 | uint64(b&0x7f)
+		if b&0x80 == 0 {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("VarInt is too long")
+}
+
+// writeHash writes the 32 byte hash from its hex string
+func writeHash(buf *bytes.Buffer, h string) error {
+	p, err := hex.DecodeString(h)
+	if err != nil {
+		return err
+	}
+	if len(p) != 32 {
+		return fmt.Errorf("Invalid hash length %d: %s", len(p), h)
+	}
+	buf.Write(p)
+	return nil
+}
+
+// skipExpansion reads past a varint sized block header expansion area
+func skipExpansion(buf *bytes.Buffer) error {
+	n, err := readVarInt(buf)
+	if err != nil {
+		return err
+	}
+	if uint64(buf.Len()) < n {
+		return fmt.Errorf("Header expansion area is too short")
+	}
+	buf.Next(int(n))
+	return nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// WalletNewTransaction creates a new empty transaction in fctwallet under the
+// key. The key names the transaction in the following calls until it is
+// submitted or deleted.
+func WalletNewTransaction(key string) error {
+	_, err := walletPost(
+		fmt.Sprintf("factoid-new-transaction/%s", key), nil)
+	return err
+}
+
+// WalletDeleteTransaction removes the transaction key from fctwallet.
+func WalletDeleteTransaction(key string) error {
+	_, err := walletPost(
+		fmt.Sprintf("factoid-delete-transaction/%s", key), nil)
+	return err
+}
+
+// WalletAddInput adds an input of amount factoshis from the wallet address
+// name to the transaction key.
+func WalletAddInput(key, name string, amount Factoshi) error {
+	_, err := walletPost("factoid-add-input/", url.Values{
+		"key":    {key},
+		"name":   {name},
+		"amount": {amount.String()},
+	})
+	return err
+}
+
+// WalletAddOutput adds a Factoid output of amount factoshis to the address,
+// given either by wallet name or as a human readable Factoid address.
+func WalletAddOutput(key, addr string, amount Factoshi) error {
+	_, err := walletPost("factoid-add-output/", url.Values{
+		"key":    {key},
+		"name":   {addr},
+		"amount": {amount.String()},
+	})
+	return err
+}
+
+// WalletAddECOutput adds an Entry Credit output purchasing amount factoshis
+// worth of Entry Credits for the address, given either by wallet name or as a
+// human readable Entry Credit address.
+func WalletAddECOutput(key, addr string, amount Factoshi) error {
+	_, err := walletPost("factoid-add-ecoutput/", url.Values{
+		"key":    {key},
+		"name":   {addr},
+		"amount": {amount.String()},
+	})
+	return err
+}
+
+// WalletAddFee adds the transaction fee to the input from the wallet address
+// name.
+func WalletAddFee(key, name string) error {
+	_, err := walletPost("factoid-add-fee/", url.Values{
+		"key":  {key},
+		"name": {name},
+	})
+	return err
+}
+
+// WalletSignTransaction signs every input of the transaction key.
+func WalletSignTransaction(key string) error {
+	_, err := walletPost(
+		fmt.Sprintf("factoid-sign-transaction/%s", key), nil)
+	return err
+}
+
+// WalletSubmitTransaction sends the signed transaction key to the factom
+// network and returns the response from fctwallet.
+func WalletSubmitTransaction(key string) (string, error) {
+	j, err := json.Marshal(struct{ Transaction string }{key})
+	if err != nil {
+		return "", err
+	}
+	return walletPost(
+		fmt.Sprintf("factoid-submit/%s", url.QueryEscape(string(j))), nil)
+}
+
+// SendFactoids sends amount factoshis from the wallet address name to the
+// Factoid address, paying the fee from the same wallet address.
+func SendFactoids(from, to string, amount Factoshi) (string, error) {
+	return walletTransaction(from, func(key string) error {
+		return WalletAddOutput(key, to, amount)
+	}, amount)
+}
+
+// BuyEntryCredits converts amount factoshis from the wallet address name into
+// Entry Credits for the EC address, paying the fee from the same wallet
+// address.
+func BuyEntryCredits(from, to string, amount Factoshi) (string, error) {
+	return walletTransaction(from, func(key string) error {
+		return WalletAddECOutput(key, to, amount)
+	}, amount)
+}
+
+// walletTransaction runs a complete fctwallet transaction paying amount from
+// the wallet address name to the output added by addOutput. If any step fails
+// the partial transaction is deleted from the wallet.
+func walletTransaction(name string, addOutput func(string) error, amount Factoshi) (string, error) {
+	key := fmt.Sprintf("tx%d", time.Now().UnixNano())
+
+	if err := WalletNewTransaction(key); err != nil {
+		return "", err
+	}
+
+	steps := []func() error{
+		func() error { return WalletAddInput(key, name, amount) },
+		func() error { return addOutput(key) },
+		func() error { return WalletAddFee(key, name) },
+		func() error { return WalletSignTransaction(key) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			WalletDeleteTransaction(key)
+			return "", err
+		}
+	}
+
+	r, err := WalletSubmitTransaction(key)
+	if err != nil {
+		WalletDeleteTransaction(key)
+		return "", err
+	}
+	return r, nil
+}
+
+// walletPost posts the form to the fctwallet api path and returns the
+// Response of a successful call.
+func walletPost(path string, form url.Values) (string, error) {
+	resp, err := http.PostForm(
+		fmt.Sprintf("http://%s/v1/%s", serverFct, path), form)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	type x struct {
+		Response string
+		Success  bool
+	}
+	b := new(x)
+	if err := json.Unmarshal(body, b); err != nil {
+		return "", fmt.Errorf("%s: %s", err, body)
+	}
+
+	if !b.Success {
+		return "", errors.New(b.Response)
+	}
+
+	return b.Response, nil
+}
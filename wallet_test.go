@@ -0,0 +1,80 @@
+package factom_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/FactomProject/factom"
+)
+
+// walletServer answers fctwallet calls, failing the step fail, and records the
+// step of each call.
+type walletServer struct {
+	fail  string
+	steps []string
+}
+
+func newWalletServer(t *testing.T, fail string) *walletServer {
+	s := &walletServer{fail: fail}
+	newTestServer(t, map[string]http.HandlerFunc{"/v1/": func(w http.ResponseWriter, r *http.Request) {
+		step := strings.TrimPrefix(r.URL.Path, "/v1/")
+		step = step[:strings.Index(step, "/")]
+		s.steps = append(s.steps, step)
+		if s.fail != "" && step == s.fail {
+			fmt.Fprint(w, `{"Response":"100% broken","Success":false}`)
+			return
+		}
+		fmt.Fprint(w, `{"Response":"ok","Success":true}`)
+	}})
+	return s
+}
+
+func TestSendFactoids(t *testing.T) {
+	s := newWalletServer(t, "")
+	if r, err := factom.SendFactoids("app", "FA1", 1e8); err != nil || r != "ok" {
+		t.Fatalf("%q %v", r, err)
+	}
+	want := "factoid-new-transaction factoid-add-input factoid-add-output " +
+		"factoid-add-fee factoid-sign-transaction factoid-submit"
+	if got := strings.Join(s.steps, " "); got != want {
+		t.Errorf("steps %s", got)
+	}
+
+	s = newWalletServer(t, "")
+	if _, err := factom.BuyEntryCredits("app", "EC1", 1e8); err != nil {
+		t.Fatal(err)
+	}
+	if s.steps[2] != "factoid-add-ecoutput" {
+		t.Errorf("steps %v", s.steps)
+	}
+}
+
+func TestSendFactoidsFailure(t *testing.T) {
+	for _, fail := range []string{
+		"factoid-add-input",
+		"factoid-add-output",
+		"factoid-add-fee",
+		"factoid-sign-transaction",
+		"factoid-submit",
+	} {
+		s := newWalletServer(t, fail)
+		_, err := factom.SendFactoids("app", "FA1", 1e8)
+		if err == nil || err.Error() != "100% broken" {
+			t.Errorf("%s: wrong error %v", fail, err)
+		}
+		// the partial transaction is deleted after the failed step
+		if n := len(s.steps); n < 2 || s.steps[n-2] != fail || s.steps[n-1] != "factoid-delete-transaction" {
+			t.Errorf("%s: steps %v", fail, s.steps)
+		}
+	}
+
+	s := newWalletServer(t, "factoid-new-transaction")
+	if _, err := factom.SendFactoids("app", "FA1", 1e8); err == nil {
+		t.Error("failed new transaction was not reported")
+	}
+	if len(s.steps) != 1 {
+		t.Errorf("steps after a failed new transaction %v", s.steps)
+	}
+}
@@ -0,0 +1,378 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// WriteStatus is the final state of a WriteEntry or CreateChain call.
+type WriteStatus int
+
+const (
+	// WriteRevealed means the commit and the reveal were both accepted.
+	WriteRevealed WriteStatus = iota
+
+	// WriteAlreadyRevealed means the reveal was refused because the Entry
+	// is already in the network.
+	WriteAlreadyRevealed
+
+	// WriteRevealTimeout means the commit was accepted but the reveal was
+	// not before the timeout, or before factomd failed to answer whether
+	// the Entry is already in the network. The commit remains paid for and the Entry may
+	// still be revealed with RevealEntry or RevealChain.
+	WriteRevealTimeout
+
+	// WriteAlreadyExists means the Entry or Chain was found before the
+	// commit, so nothing was paid for or sent.
+	WriteAlreadyExists
+
+	// WriteRevealFailed means the commit was acknowledged but factomd
+	// refused the reveal, which retrying will not change. The commit remains
+	// paid for.
+	WriteRevealFailed
+)
+
+func (s WriteStatus) String() string {
+	switch s {
+	case WriteRevealed:
+		return "revealed"
+	case WriteAlreadyRevealed:
+		return "already revealed"
+	case WriteRevealTimeout:
+		return "reveal timeout"
+	case WriteAlreadyExists:
+		return "already exists"
+	case WriteRevealFailed:
+		return "reveal failed"
+	}
+	return fmt.Sprintf("WriteStatus(%d)", int(s))
+}
+
+// WriteResult is the outcome of writing an Entry or Chain. The CommitTxID and
+// EntryHash may be passed to WaitFor to follow the write into a Directory
+// Block.
+type WriteResult struct {
+	CommitTxID string
+	EntryHash  string
+	ChainID    string
+	Status     WriteStatus
+}
+
+// Writer runs the commit and reveal of Entries and Chains paid for by the
+// fctwallet Entry Credit address Name.
+type Writer struct {
+	Name string
+
+	// Timeout is how long to keep retrying the reveal after a successful
+	// commit, waiting RetryInterval between attempts.
+	Timeout       time.Duration
+	RetryInterval time.Duration
+
+	// Journal, if set, records every write so that reveals interrupted by a
+	// crash can be replayed with Journal.Replay.
+	Journal *Journal
+
+	// CheckExists looks up each Entry, or the Chain of each new Chain, before
+	// paying for it, and returns WriteAlreadyExists rather than paying
+	// twice. It also refuses to pay for an Entry in a Chain that does not
+	// exist. Seen, if set, is consulted before the network and records every
+	// Entry known to be written.
+	CheckExists bool
+	Seen        SeenSet
+}
+
+func NewWriter(name string) *Writer {
+	w := new(Writer)
+	w.Name = name
+	w.Timeout = 2 * time.Minute
+	w.RetryInterval = 2 * time.Second
+
+	return w
+}
+
+// WriteEntry commits and reveals the Entry paid for by the wallet Entry Credit
+// address name.
+func WriteEntry(e *Entry, name string) (*WriteResult, error) {
+	return NewWriter(name).WriteEntry(e)
+}
+
+// CreateChain commits and reveals the new Chain paid for by the wallet Entry
+// Credit address name.
+func CreateChain(c *Chain, name string) (*WriteResult, error) {
+	return NewWriter(name).CreateChain(c)
+}
+
+// WriteEntryIfNew is WriteEntry with CheckExists set, so an Entry already in
+// the network is not paid for again and an Entry in a missing Chain is not
+// paid for at all.
+func WriteEntryIfNew(e *Entry, name string) (*WriteResult, error) {
+	w := NewWriter(name)
+	w.CheckExists = true
+	return w.WriteEntry(e)
+}
+
+// CreateChainIfNew is CreateChain with CheckExists set, so a Chain already in
+// the network is not paid for again.
+func CreateChainIfNew(c *Chain, name string) (*WriteResult, error) {
+	w := NewWriter(name)
+	w.CheckExists = true
+	return w.CreateChain(c)
+}
+
+// CommitEntryIfNew is CommitEntry for an Entry not yet in the network, in a
+// Chain that exists. It reports whether the commit was sent; an Entry already
+// in the network is not paid for again.
+func CommitEntryIfNew(e *Entry, name string) (bool, error) {
+	w := NewWriter(name)
+	w.CheckExists = true
+	p, err := w.commitEntry(e)
+	if err != nil {
+		return false, err
+	}
+	return p.result.Status != WriteAlreadyExists, nil
+}
+
+// CommitChainIfNew is CommitChain for a Chain not yet in the network. It
+// reports whether the commit was sent.
+func CommitChainIfNew(c *Chain, name string) (bool, error) {
+	w := NewWriter(name)
+	w.CheckExists = true
+	p, err := w.commitChain(c)
+	if err != nil {
+		return false, err
+	}
+	return p.result.Status != WriteAlreadyExists, nil
+}
+
+func (w *Writer) WriteEntry(e *Entry) (*WriteResult, error) {
+	p, err := w.commitEntry(e)
+	if err != nil {
+		return nil, err
+	}
+	return w.reveal(p)
+}
+
+func (w *Writer) CreateChain(c *Chain) (*WriteResult, error) {
+	p, err := w.commitChain(c)
+	if err != nil {
+		return nil, err
+	}
+	return w.reveal(p)
+}
+
+// pendingWrite is an Entry or Chain that has been committed and is waiting to
+// be revealed.
+type pendingWrite struct {
+	result *WriteResult
+	reveal func() error
+}
+
+func newPendingWrite(e *Entry, txid string, reveal func() error) *pendingWrite {
+	r := new(WriteResult)
+	r.CommitTxID = txid
+	r.EntryHash = hex.EncodeToString(e.Hash())
+	r.ChainID = e.ChainID
+
+	return &pendingWrite{r, reveal}
+}
+
+func (w *Writer) commitEntry(e *Entry) (*pendingWrite, error) {
+	c, err := NewEntryCommit(e)
+	if err != nil {
+		return nil, err
+	}
+	p := newPendingWrite(e, c.TxID(),
+		func() error { return RevealEntry(e) })
+	if w.CheckExists {
+		if ok, err := w.exists(p.result, false); err != nil || ok {
+			return p, err
+		}
+	}
+	if err := w.commit(p, e, false, "commit-entry", c.MarshalUnsignedBinary()); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (w *Writer) commitChain(c *Chain) (*pendingWrite, error) {
+	cc, err := NewChainCommit(c)
+	if err != nil {
+		return nil, err
+	}
+	p := newPendingWrite(c.FirstEntry, cc.TxID(),
+		func() error { return RevealChain(c) })
+	if w.CheckExists {
+		if ok, err := w.exists(p.result, true); err != nil || ok {
+			return p, err
+		}
+	}
+	if err := w.commit(p, c.FirstEntry, true, "commit-chain", cc.MarshalUnsignedBinary()); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// exists reports whether the write is already in the Seen set or the network,
+// setting its status to WriteAlreadyExists if it is. For a new Chain it checks
+// the Chain, and for an Entry it checks the Entry and that its Chain exists.
+func (w *Writer) exists(r *WriteResult, chain bool) (bool, error) {
+	if w.Seen != nil && w.Seen.Seen(r.EntryHash) {
+		r.Status = WriteAlreadyExists
+		return true, nil
+	}
+
+	if chain {
+		ok, err := ChainExists(r.ChainID)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			r.Status = WriteAlreadyExists
+			w.see(r)
+		}
+		return ok, nil
+	}
+
+	ok, err := entryExists(r.EntryHash)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		r.Status = WriteAlreadyExists
+		w.see(r)
+		return true, nil
+	}
+	ok, err = ChainExists(r.ChainID)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrChainNotFound, r.ChainID)
+	}
+	return false, nil
+}
+
+func (w *Writer) see(r *WriteResult) {
+	if w.Seen != nil {
+		w.Seen.Add(r.EntryHash)
+	}
+}
+
+// commit sends the commit message to the wallet, journaling the Entry first
+// if there is a Journal. A write the Journal fails to record fails, even if
+// the commit was sent.
+func (w *Writer) commit(p *pendingWrite, e *Entry, chain bool, path string, msg []byte) error {
+	if w.Journal != nil {
+		if err := w.Journal.begin(p.result, e, chain); err != nil {
+			return err
+		}
+	}
+
+	if err := walletCommit(path, w.Name, msg); err != nil {
+		if jerr := w.journal(p.result, JournalFailed, err); jerr != nil {
+			return fmt.Errorf("%w; %v", err, jerr)
+		}
+		return err
+	}
+	return w.journal(p.result, JournalCommitted, nil)
+}
+
+// journal records the new state of the write if there is a Journal.
+func (w *Writer) journal(r *WriteResult, state string, err error) error {
+	if w.Journal == nil {
+		return nil
+	}
+	if jerr := w.Journal.record(r, state, err); jerr != nil {
+		return fmt.Errorf("Journal of %s: %w", r.EntryHash, jerr)
+	}
+	return nil
+}
+
+// reveal reveals the committed write until the reveal is accepted, the Entry
+// is found to be in the network already, or the Timeout passes. A reveal sent
+// before the network has acknowledged the commit is refused, so only those
+// refusals and reveals that did not reach factomd are retried. A write that
+// times out is left outstanding in the Journal, and one refused for good is
+// recorded as failed.
+func (w *Writer) reveal(p *pendingWrite) (*WriteResult, error) {
+	r := p.result
+	if r.Status == WriteAlreadyExists {
+		return r, nil
+	}
+
+	deadline := time.Now().Add(w.Timeout)
+	for {
+		err := p.reveal()
+		if err == nil {
+			r.Status = WriteRevealed
+			return r, w.revealed(r)
+		}
+		ok, gerr := entryExists(r.EntryHash)
+		var uerr *url.Error
+		if gerr != nil && !errors.As(gerr, &uerr) {
+			r.Status = WriteRevealTimeout
+			return r, fmt.Errorf("Reveal of %s: %s", r.EntryHash, gerr)
+		}
+		if ok {
+			r.Status = WriteAlreadyRevealed
+			return r, w.revealed(r)
+		}
+		if retry, rerr := retryReveal(r, err); !retry {
+			r.Status = WriteRevealFailed
+			if jerr := w.journal(r, JournalFailed, rerr); jerr != nil {
+				return r, fmt.Errorf("%w; %v", rerr, jerr)
+			}
+			return r, rerr
+		}
+		if time.Now().Add(w.RetryInterval).After(deadline) {
+			r.Status = WriteRevealTimeout
+			return r, fmt.Errorf("Reveal of %s timed out: %s", r.EntryHash, err)
+		}
+		time.Sleep(w.RetryInterval)
+	}
+}
+
+// retryReveal reports whether the failed reveal of the write may yet be
+// accepted: the reveal did not reach factomd, or factomd refused it before
+// acknowledging the commit. Otherwise it returns the error to give up with,
+// which is the JSON-RPC error if factomd refused the commit lookup.
+func retryReveal(r *WriteResult, err error) (bool, error) {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		return true, nil
+	}
+
+	s, aerr := CommitAck(r.CommitTxID)
+	if aerr != nil {
+		var jerr *JSONError
+		if errors.As(aerr, &jerr) {
+			return false, aerr
+		}
+		return true, nil
+	}
+	if s.CommitStatus < AckTransactionACK {
+		return true, nil
+	}
+	return false, fmt.Errorf("Reveal of %s refused: %s", r.EntryHash, err)
+}
+
+func (w *Writer) revealed(r *WriteResult) error {
+	w.see(r)
+	return w.journal(r, JournalRevealed, nil)
+}
+
+func (r *WriteResult) String() string {
+	var s string
+	s += fmt.Sprintln("CommitTxID:", r.CommitTxID)
+	s += fmt.Sprintln("EntryHash:", r.EntryHash)
+	s += fmt.Sprintln("ChainID:", r.ChainID)
+	s += fmt.Sprintln("Status:", r.Status)
+	return s
+}
@@ -0,0 +1,164 @@
+package factom_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+// entryNotFound answers an Entry lookup as factomd does for a missing Entry.
+func entryNotFound(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "Entry not found", http.StatusBadRequest)
+}
+
+func TestWriteEntry(t *testing.T) {
+	reveals, refuse, ack := 0, 1, `{"commitdata":{"status":"Unknown"}}`
+	newTestServer(t, map[string]http.HandlerFunc{
+		"/v1/commit-entry/":  nil,
+		"/v1/entry-by-hash/": entryNotFound,
+		"/v1/reveal-entry/": func(w http.ResponseWriter, r *http.Request) {
+			if reveals++; reveals <= refuse {
+				http.Error(w, "reveal refused", http.StatusBadRequest)
+			}
+		},
+		"/v2": func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(ack, `{"code"`) {
+				w.Write([]byte(`{"jsonrpc":"2.0","id":0,"error":` + ack + `}`))
+				return
+			}
+			w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":` + ack + `}`))
+		},
+	})
+
+	e := factom.NewEntry()
+	if err := e.UnmarshalJSON(jsonentry); err != nil {
+		t.Error(err)
+	}
+
+	w := factom.NewWriter("app")
+	w.RetryInterval = time.Millisecond
+	r, err := w.WriteEntry(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != factom.WriteRevealed || reveals != 2 {
+		t.Errorf("wrong result after %d reveals\n%s", reveals, r)
+	}
+
+	reveals, refuse = 0, 1000
+	w.Timeout = 5 * time.Millisecond
+	r, err = w.WriteEntry(e)
+	if err == nil || r.Status != factom.WriteRevealTimeout {
+		t.Errorf("reveal did not time out\n%s", r)
+	}
+
+	// a reveal refused after the commit is acknowledged is not retried
+	reveals, ack = 0, `{"commitdata":{"status":"TransactionACK"}}`
+	w.Timeout = time.Minute
+	r, err = w.WriteEntry(e)
+	if err == nil || r.Status != factom.WriteRevealFailed || reveals != 1 {
+		t.Errorf("refused reveal was retried %d times: %v\n%s", reveals, err, r)
+	}
+
+	reveals, ack = 0, `{"code":-32602,"message":"Invalid params"}`
+	r, err = w.WriteEntry(e)
+	var jerr *factom.JSONError
+	if !errors.As(err, &jerr) || r.Status != factom.WriteRevealFailed || reveals != 1 {
+		t.Errorf("reveal was retried %d times after a JSON-RPC error: %v\n%s", reveals, err, r)
+	}
+}
+
+func TestWriteEntryExists(t *testing.T) {
+	commits, found, chain, broken := 0, false, true, false
+	newTestServer(t, map[string]http.HandlerFunc{
+		"/v1/commit-entry/": func(w http.ResponseWriter, r *http.Request) {
+			commits++
+		},
+		"/v1/reveal-entry/": func(w http.ResponseWriter, r *http.Request) {
+			found = true
+		},
+		"/v1/entry-by-hash/": func(w http.ResponseWriter, r *http.Request) {
+			if broken {
+				http.Error(w, "database unavailable", http.StatusInternalServerError)
+				return
+			}
+			if !found {
+				entryNotFound(w, r)
+				return
+			}
+			w.Write(jsonentry)
+		},
+		"/v1/chain-head/": func(w http.ResponseWriter, r *http.Request) {
+			if !chain {
+				http.Error(w, "Missing Chain Head", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"ChainHead":"1111111111111111111111111111111111111111111111111111111111111111"}`))
+		},
+	})
+
+	e := factom.NewEntry()
+	if err := e.UnmarshalJSON(jsonentry); err != nil {
+		t.Error(err)
+	}
+
+	w := factom.NewWriter("app")
+	w.RetryInterval = time.Millisecond
+	w.CheckExists = true
+	w.Seen = factom.NewMemorySeenSet()
+
+	r, err := w.WriteEntry(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != factom.WriteRevealed || commits != 1 {
+		t.Errorf("wrong result after %d commits\n%s", commits, r)
+	}
+	if !w.Seen.Seen(r.EntryHash) {
+		t.Error("written Entry was not added to the Seen set")
+	}
+
+	// found by the Seen set and then by the network
+	for _, seen := range []factom.SeenSet{w.Seen, factom.NewMemorySeenSet()} {
+		w.Seen = seen
+		r, err = w.WriteEntry(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Status != factom.WriteAlreadyExists || commits != 1 {
+			t.Errorf("existing Entry was paid for again after %d commits\n%s", commits, r)
+		}
+	}
+
+	// the package level variants check the network
+	if ok, err := factom.CommitEntryIfNew(e, "app"); ok || err != nil || commits != 1 {
+		t.Errorf("existing Entry was committed again: %v %v", ok, err)
+	}
+	if r, err := factom.WriteEntryIfNew(e, "app"); err != nil || r.Status != factom.WriteAlreadyExists || commits != 1 {
+		t.Errorf("existing Entry was written again: %v\n%s", err, r)
+	}
+
+	found = false
+	if ok, err := factom.CommitEntryIfNew(e, "app"); !ok || err != nil || commits != 2 {
+		t.Errorf("new Entry was not committed: %v %v", ok, err)
+	}
+
+	found, chain = false, false
+	w.Seen = nil
+	if _, err := w.WriteEntry(e); !errors.Is(err, factom.ErrChainNotFound) || commits != 2 {
+		t.Errorf("Entry in a missing Chain was paid for after %d commits: %v", commits, err)
+	}
+	if _, err := factom.CommitEntryIfNew(e, "app"); !errors.Is(err, factom.ErrChainNotFound) || commits != 2 {
+		t.Errorf("Entry in a missing Chain was committed after %d commits: %v", commits, err)
+	}
+
+	// only factomd answering that the Entry is missing means it is new
+	chain, broken = true, true
+	if _, err := w.WriteEntry(e); err == nil || commits != 2 {
+		t.Errorf("Entry was paid for after a failed lookup after %d commits", commits)
+	}
+}